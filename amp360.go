@@ -4,16 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"reflect"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -46,9 +42,12 @@ func NewClient(defaultBaseURL string, httpClient *http.Client) *Client {
 	c.CompaniesService = &CompaniesService{client: c}
 	c.ModelsService = &ModelsService{client: c}
 	c.TerminalsService = &TerminalsService{client: c}
+	c.ApplicationsService = &ApplicationsService{client: c}
+	c.FirmwareService = &FirmwareService{client: c}
 	return c
 }
 
+// Logger is the logging interface used by the client, see SetLogger.
 type Logger interface {
 	Debugf(format string, args ...interface{})
 	Infof(format string, args ...interface{})
@@ -62,20 +61,30 @@ type Client struct {
 
 	BaseURL   *url.URL
 	UserAgent string
-	apiKey    string
-
-	TemplatesService *TemplatesService
-	CompaniesService *CompaniesService
-	ModelsService    *ModelsService
-	TerminalsService *TerminalsService
+	creds     CredentialsProvider
+	retry     *RetryPolicy
+	limiter   *RateLimiter
+	logger    Logger
+	cache     *CachePolicy
+	metrics   Metrics
+	tracer    Tracer
+
+	TemplatesService    *TemplatesService
+	CompaniesService    *CompaniesService
+	ModelsService       *ModelsService
+	TerminalsService    *TerminalsService
+	ApplicationsService *ApplicationsService
+	FirmwareService     *FirmwareService
 }
 
 type service struct {
 	client *Client
 }
 
+// SetAPIKey makes the client authenticate with a static API key. It may be
+// called while requests are running.
 func (c *Client) SetAPIKey(apiKey string) {
-	c.apiKey = apiKey
+	c.SetCredentials(StaticCredentials(apiKey))
 }
 
 func (c *Client) SetTransport(roundTripper http.RoundTripper) {
@@ -91,7 +100,14 @@ func (c *Client) Client() *http.Client {
 }
 
 func (c *Client) NewRequest(method string, path url.URL, body interface{}) (*http.Request, error) {
-	return c.newRequestCtx(context.Background(), method, path, body)
+	req, err := c.newRequestCtx(context.Background(), method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	return req, nil
 }
 
 func (c *Client) newMultiPartRequestCtx(ctx context.Context, method string, path url.URL, body interface{}) (*http.Request, error) {
@@ -102,7 +118,6 @@ func (c *Client) newMultiPartRequestCtx(ctx context.Context, method string, path
 	}
 
 	req.Header.Add("Accept", "application/json; charset=utf-8")
-	req.Header.Add("Authorization", c.apiKey)
 
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
@@ -129,7 +144,6 @@ func (c *Client) newRequestCtx(ctx context.Context, method string, path url.URL,
 	}
 
 	req.Header.Add("Accept", "application/json; charset=utf-8")
-	req.Header.Add("Authorization", c.apiKey)
 
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
@@ -137,91 +151,126 @@ func (c *Client) newRequestCtx(ctx context.Context, method string, path url.URL,
 	return req, nil
 }
 
-func (c *Client) processRequest(ctx context.Context, method string, path url.URL, body interface{}, result interface{}) error {
-	req, err := c.newRequestCtx(ctx, method, path, body)
+func (c *Client) processRequest(ctx context.Context, method string, path url.URL, body interface{}, result interface{}) (err error) {
+	ctx, span := c.startOperation(ctx, method, &path)
+	defer func() { endSpan(span, err) }()
+
+	cache, key, ttl := c.cacheFor(method, path)
+	var cached *CacheEntry
+	if cache != nil {
+		if e, ok := cache.Get(key); ok {
+			if time.Now().Before(e.Expires) {
+				c.Logger().Debugf("amp360: %s %s: cached", method, key)
+				span.SetAttributes(BoolAttr(AttrCacheHit, true))
+				return c.decode(method, key, e.Body, result)
+			}
+			cached = e
+		}
+	}
+
+	defer c.invalidate(method, path)
+	res, attempts, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := c.newRequestCtx(ctx, method, path, body)
+		if err == nil && cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+		return req, err
+	})
+	traceResponse(span, res, attempts)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
-	res, err := c.client.Do(req)
+	raw, err := io.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
 
-	resp := Response{
-		Data: result,
+	if cached != nil && res.StatusCode == http.StatusNotModified {
+		e := *cached
+		e.Expires = time.Now().Add(ttl)
+		cache.Set(key, &e)
+		return c.decode(method, key, e.Body, result)
+	}
+	if res.StatusCode != http.StatusOK {
+		apiErr := newAPIError(res, raw)
+		apiErr.Attempts = attempts
+		return apiErr
 	}
 
-	err = json.NewDecoder(res.Body).Decode(&resp)
-	if err != nil {
+	if err := c.decode(method, res.Request.URL.Path, raw, result); err != nil {
 		return err
 	}
-	switch res.StatusCode {
-	case http.StatusOK:
-		return err
-	case http.StatusBadRequest:
-		return fmt.Errorf("api err: %s", resp.Message)
-	case http.StatusUnauthorized:
-		err = ErrIvalidToken
-	case http.StatusForbidden:
-		err = ErrNoPermission
-	case http.StatusConflict:
-		err = ErrConflict
-	case http.StatusNotFound:
-		err = ErrEntityNotFound
-	case http.StatusBadGateway:
-		if strings.Contains(resp.Message, "Failed to find") {
-			return ErrEntityNotFound
-		}
-		return fmt.Errorf("api err: %s", resp.Message)
-	default:
-		err = ErrUnknown
+	if cache != nil {
+		cache.Set(key, &CacheEntry{
+			Body:         raw,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			Expires:      time.Now().Add(ttl),
+		})
 	}
+	return nil
+}
 
-	return err
+// decode decodes the body of a response to a request to path into result.
+func (c *Client) decode(method, path string, raw []byte, result interface{}) error {
+	resp := Response{
+		Data: result,
+	}
+	if err := json.NewDecoder(bytes.NewReader(raw)).Decode(&resp); err != nil {
+		c.Logger().Errorf("amp360: decode %s %s: %v", method, path, err)
+		return err
+	}
+	return nil
 }
 
-func (c *Client) processBulkRequest(ctx context.Context, method string, path url.URL, params map[string]string, paramfiles map[string]string, u, f interface{}) error {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+func (c *Client) processBulkRequest(ctx context.Context, method string, path url.URL, up *ParamsUpload, u, f interface{}) (err error) {
+	ctx, span := c.startOperation(ctx, method, &path)
+	defer func() { endSpan(span, err) }()
 
-	for p, filePath := range paramfiles {
-		file, err := os.Open(filePath)
+	replayable := up.replayable()
+	defer c.invalidate(method, path)
+	res, attempts, err := c.do(ctx, func() (*http.Request, error) {
+		body, contentType, length, err := newBulkBody(up)
 		if err != nil {
-			return err
-		}
-		defer file.Close()
-		part, err := writer.CreateFormFile(p, filepath.Base(file.Name()))
-		if err != nil {
-			return err
+			return nil, err
 		}
-		io.Copy(part, file)
+		req, err := c.newMultiPartRequestCtx(ctx, method, path, body)
 		if err != nil {
-			return err
+			body.Close()
+			return nil, err
 		}
-	}
-
-	for key, val := range params {
-		err := writer.WriteField(key, val)
-		if err != nil {
-			return err
+		req.Header.Set("Content-Type", contentType)
+		req.ContentLength = length
+		if replayable {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, _, _, err := newBulkBody(up)
+				return body, err
+			}
 		}
-	}
-
-	writer.Close()
-	req, err := c.newMultiPartRequestCtx(ctx, method, path, body)
+		return req, nil
+	})
+	traceResponse(span, res, attempts)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	res, err := c.client.Do(req)
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	if res.StatusCode == http.StatusNotFound {
-		return ErrNotFound
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := newAPIError(res, raw)
+		apiErr.Attempts = attempts
+		return apiErr
 	}
 
 	resp := BulkResponse{
@@ -229,23 +278,52 @@ func (c *Client) processBulkRequest(ctx context.Context, method string, path url
 		Updated: u,
 	}
 
-	err = json.NewDecoder(res.Body).Decode(&resp)
+	err = json.NewDecoder(bytes.NewReader(raw)).Decode(&resp)
 	if err != nil {
+		c.Logger().Errorf("amp360: decode %s %s: %v", method, res.Request.URL.Path, err)
 		return err
 	}
 
-	if res.StatusCode == http.StatusUnauthorized {
-		return ErrUnauthorized
-	}
 	if !resp.Success {
-		err = fmt.Errorf("api err: %s", resp.Message)
+		apiErr := newAPIError(res, raw)
+		apiErr.Attempts = attempts
+		return apiErr
 	}
-	return err
+	if updated, failed, ok := bulkCounts(raw); ok {
+		span.SetAttributes(IntAttr(AttrBulkUpdated, updated), IntAttr(AttrBulkFailed, failed))
+		if m := c.Metrics(); m != nil {
+			m.ObserveBulk(c.operation(ctx, method, res.Request.URL), updated, failed)
+		}
+	}
+	return nil
 }
 
+// Do sends an API request and returns the API response. The request is
+// retried according to the retry policy of the client unless it has a body
+// that cannot be rewound through req.GetBody.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
-	resp, err := c.client.Do(req)
+	opCtx, span := c.startOperation(ctx, req.Method, req.URL)
+	first := true
+	resp, attempts, err := c.do(opCtx, func() (*http.Request, error) {
+		// Each attempt sends a clone, so the headers added to it are not
+		// left on req. The first attempt reads the body of req.
+		r := req.Clone(ctx)
+		if first {
+			first = false
+			return r, nil
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		return r, nil
+	})
+	traceResponse(span, resp, attempts)
+	endSpan(span, err)
 	if err != nil {
 		select {
 		case <-ctx.Done():
@@ -258,6 +336,33 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
+// fetch sends the request and decodes the data of the response into a new T.
+func fetch[T any](ctx context.Context, c *Client, method string, path url.URL, body interface{}) (*T, error) {
+	v := new(T)
+	if err := c.processRequest(ctx, method, path, body, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// fetchList is fetch for endpoints accepting query options.
+func fetchList[T any](ctx context.Context, c *Client, path string, opt interface{}) (*T, error) {
+	u, err := addOptions(path, opt)
+	if err != nil {
+		return nil, err
+	}
+	return fetch[T](ctx, c, http.MethodGet, *u, nil)
+}
+
+// bulk sends a bulk parameters request and collects its result.
+func bulk(ctx context.Context, c *Client, path url.URL, up *ParamsUpload) (*BulkResult, error) {
+	r := &BulkResult{}
+	if err := c.processBulkRequest(ctx, http.MethodPost, path, up, &r.Updated, &r.Failed); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 func addOptions(s string, opt interface{}) (*url.URL, error) {
 	v := reflect.ValueOf(opt)
 	if v.Kind() == reflect.Ptr && v.IsNil() {
@@ -0,0 +1,103 @@
+// Package amp360otel traces the calls of AMP360 clients with OpenTelemetry.
+//
+//	client.SetTracer(amp360otel.New(nil, nil))
+//
+// Every operation, e.g. TerminalsService.Update, gets an internal span with
+// a client span per HTTP attempt, whose trace context is sent in the headers
+// of the request.
+package amp360otel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/andrei-cloud/amp360"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans.
+const ScopeName = "github.com/andrei-cloud/amp360"
+
+// Options configures the tracer.
+type Options struct {
+	// Propagator writes the trace context to the request headers, the
+	// global one if nil.
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracer implements amp360.Tracer with OpenTelemetry.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ amp360.Tracer = (*Tracer)(nil)
+
+// New returns a tracer opening its spans with tp, the global tracer provider
+// if nil.
+func New(tp trace.TracerProvider, opt *Options) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	p := opt.Propagator
+	if p == nil {
+		p = otel.GetTextMapPropagator()
+	}
+	return &Tracer{tracer: tp.Tracer(ScopeName), propagator: p}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, kind amp360.SpanKind, attrs ...amp360.Attribute) (context.Context, amp360.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(spanKind(kind)), trace.WithAttributes(convert(attrs)...))
+	return ctx, spanAdapter{span}
+}
+
+// spanKind converts kind to its OpenTelemetry counterpart.
+func spanKind(kind amp360.SpanKind) trace.SpanKind {
+	if kind == amp360.SpanKindClient {
+		return trace.SpanKindClient
+	}
+	return trace.SpanKindInternal
+}
+
+func (t *Tracer) Inject(ctx context.Context, h http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s spanAdapter) SetAttributes(attrs ...amp360.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s spanAdapter) SetError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s spanAdapter) End() {
+	s.span.End()
+}
+
+func convert(attrs []amp360.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		}
+	}
+	return kvs
+}
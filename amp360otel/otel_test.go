@@ -0,0 +1,98 @@
+package amp360otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	srv := amp360test.NewServer()
+	defer srv.Close()
+	srv.SetAPIKey("key")
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{{ID: 814, Name: "APITEST", Params: []amp360.Param{{Tag: "a", Type: "STRING"}}}},
+		Terminals: []amp360test.Terminal{{ID: 25, SerialNumber: "8000044499", Name: "T1", TemplateID: 814}},
+	})
+
+	// record the traceparent headers reaching the server
+	var mu sync.Mutex
+	var parents []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		parents = append(parents, r.Header.Get("traceparent"))
+		mu.Unlock()
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	c := amp360.NewClient(proxy.URL+amp360test.BasePath, nil)
+	c.SetAPIKey("key")
+	c.SetTracer(New(tp, &Options{Propagator: propagation.TraceContext{}}))
+	ctx := context.Background()
+
+	if _, err := c.TerminalsService.SetParams(ctx, 25, map[string]string{"a": "1"}, nil); err != nil {
+		t.Fatalf("SetParams returned error: %v", err)
+	}
+	if err := c.TerminalsService.Delete(ctx, 999); err == nil {
+		t.Fatal("Delete of a missing terminal returned no error")
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("exported %d spans, want 4", len(spans))
+	}
+	// attempt spans end before their operation span
+	attempt, op := spans[0], spans[1]
+	if op.Name != "TerminalsService.SetParams" || attempt.Name != "POST" {
+		t.Errorf("span names = %q, %q", op.Name, attempt.Name)
+	}
+	if attempt.Parent.SpanID() != op.SpanContext.SpanID() {
+		t.Error("attempt span is not a child of the operation span")
+	}
+	if op.SpanKind != trace.SpanKindInternal || attempt.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kinds = %v, %v", op.SpanKind, attempt.SpanKind)
+	}
+	wantAttrs := map[attribute.Key]attribute.Value{
+		amp360.AttrOperation:   attribute.StringValue(amp360.OpTerminalsParamsBulk),
+		amp360.AttrTerminalID:  attribute.IntValue(25),
+		amp360.AttrHTTPStatus:  attribute.IntValue(200),
+		amp360.AttrBulkUpdated: attribute.IntValue(1),
+		amp360.AttrBulkFailed:  attribute.IntValue(0),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range op.Attributes {
+		got[kv.Key] = kv.Value
+	}
+	for k, want := range wantAttrs {
+		if got[k] != want {
+			t.Errorf("operation span %s = %v, want %v", k, got[k].Emit(), want.Emit())
+		}
+	}
+
+	deleteOp := spans[3]
+	if deleteOp.Name != "TerminalsService.Delete" || deleteOp.Status.Code != codes.Error || len(deleteOp.Events) == 0 {
+		t.Errorf("failed operation span = %q %v, %d events", deleteOp.Name, deleteOp.Status, len(deleteOp.Events))
+	}
+
+	if len(parents) != 2 {
+		t.Fatalf("server got %d requests, want 2", len(parents))
+	}
+	want := "00-" + attempt.SpanContext.TraceID().String() + "-" + attempt.SpanContext.SpanID().String() + "-01"
+	if parents[0] != want {
+		t.Errorf("traceparent = %q, want %q", parents[0], want)
+	}
+}
@@ -0,0 +1,118 @@
+// Package amp360prom exports the metrics of AMP360 clients to Prometheus.
+//
+//	m, err := amp360prom.New(prometheus.DefaultRegisterer, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client.SetMetrics(m)
+//
+// The metrics are labelled by operation, e.g. "terminals.list", and never by
+// URL, so that their cardinality stays bounded.
+package amp360prom
+
+import (
+	"strconv"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures the metrics.
+type Options struct {
+	// Namespace prefixes the names of the metrics, "amp360" if empty.
+	Namespace string
+	// Buckets are the latency histogram buckets in seconds,
+	// prometheus.DefBuckets if nil.
+	Buckets []float64
+}
+
+// Metrics implements amp360.Metrics with Prometheus collectors:
+//
+//	<ns>_requests_total{operation,method,code}     calls, code is "none" without response
+//	<ns>_request_errors_total{operation}           calls without response or with an error status
+//	<ns>_request_duration_seconds{operation}       latency of the calls, retries included
+//	<ns>_request_retries_total{operation}          attempts beyond the first one
+//	<ns>_bulk_params_total{operation,result}       parameters "updated" or "failed" by bulk updates
+type Metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+	bulk     *prometheus.CounterVec
+}
+
+var _ amp360.Metrics = (*Metrics)(nil)
+
+// New returns metrics registered with reg, prometheus.DefaultRegisterer if
+// nil.
+func New(reg prometheus.Registerer, opt *Options) (*Metrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	ns := opt.Namespace
+	if ns == "" {
+		ns = "amp360"
+	}
+	buckets := opt.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "requests_total",
+			Help:      "AMP360 API calls by operation, method and status code.",
+		}, []string{"operation", "method", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "request_errors_total",
+			Help:      "AMP360 API calls that got no response or an error status.",
+		}, []string{"operation"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the AMP360 API calls, retries included.",
+			Buckets:   buckets,
+		}, []string{"operation"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "request_retries_total",
+			Help:      "Retried attempts of the AMP360 API calls.",
+		}, []string{"operation"}),
+		bulk: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "bulk_params_total",
+			Help:      "Parameters updated or failed by AMP360 bulk updates.",
+		}, []string{"operation", "result"}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.duration, m.retries, m.bulk} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) ObserveCall(s amp360.CallStats) {
+	code := "none"
+	if s.StatusCode != 0 {
+		code = strconv.Itoa(s.StatusCode)
+	}
+	m.requests.WithLabelValues(s.Operation, s.Method, code).Inc()
+	if s.Failed() {
+		m.errors.WithLabelValues(s.Operation).Inc()
+	}
+	m.duration.WithLabelValues(s.Operation).Observe(s.Duration.Seconds())
+	if s.Attempts > 1 {
+		m.retries.WithLabelValues(s.Operation).Add(float64(s.Attempts - 1))
+	}
+}
+
+func (m *Metrics) ObserveBulk(op string, updated, failed int) {
+	m.bulk.WithLabelValues(op, "updated").Add(float64(updated))
+	m.bulk.WithLabelValues(op, "failed").Add(float64(failed))
+}
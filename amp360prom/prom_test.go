@@ -0,0 +1,72 @@
+package amp360prom
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	srv := amp360test.NewServer()
+	defer srv.Close()
+	srv.SetAPIKey("key")
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{{ID: 814, Name: "APITEST", Params: []amp360.Param{{Tag: "a", Type: "STRING"}}}},
+		Terminals: []amp360test.Terminal{{ID: 25, SerialNumber: "8000044499", Name: "T1", TemplateID: 814}},
+	})
+
+	reg := prometheus.NewPedanticRegistry()
+	m, err := New(reg, nil)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	c := srv.Client()
+	c.SetAPIKey("key")
+	c.SetMetrics(m)
+	ctx := context.Background()
+
+	if _, err := c.TerminalsService.List(ctx, nil); err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if _, err := c.TerminalsService.SetParams(ctx, 25, map[string]string{"a": "1"}, nil); err != nil {
+		t.Fatalf("SetParams returned error: %v", err)
+	}
+	if err := c.TerminalsService.Delete(ctx, 999); err == nil {
+		t.Fatal("Delete of a missing terminal returned no error")
+	}
+
+	want := `
+# HELP amp360_request_errors_total AMP360 API calls that got no response or an error status.
+# TYPE amp360_request_errors_total counter
+amp360_request_errors_total{operation="terminals.delete"} 1
+# HELP amp360_requests_total AMP360 API calls by operation, method and status code.
+# TYPE amp360_requests_total counter
+amp360_requests_total{code="200",method="GET",operation="terminals.list"} 1
+amp360_requests_total{code="200",method="POST",operation="terminals.params.bulk"} 1
+amp360_requests_total{code="404",method="DELETE",operation="terminals.delete"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "amp360_requests_total", "amp360_request_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(m.duration); n != 3 {
+		t.Errorf("duration histograms = %d, want 3", n)
+	}
+	if got := testutil.ToFloat64(m.bulk.WithLabelValues(amp360.OpTerminalsParamsBulk, "updated")); got != 1 {
+		t.Errorf("bulk updated = %v, want 1", got)
+	}
+}
+
+func TestNewRegistersOnce(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := New(reg, &Options{Namespace: "x"}); err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, err := New(reg, &Options{Namespace: "x"}); err == nil {
+		t.Error("second New with the same namespace returned no error")
+	}
+}
@@ -0,0 +1,963 @@
+// Package amp360test provides an in-memory fake of the AMP360 API for tests.
+//
+//	srv := amp360test.NewServer()
+//	defer srv.Close()
+//	srv.Seed(amp360test.Data{...})
+//	c := srv.Client()
+package amp360test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+// BasePath is the path prefix the fake API is served under.
+const BasePath = "/v1/"
+
+// Default tags of the parameters matched by the tid and mid filters of the
+// terminals list.
+const (
+	DefaultTIDTag = "ACQS._1.ACQINFO.TERMINALID"
+	DefaultMIDTag = "ACQS._1.ACQINFO.MERCHANTID"
+)
+
+// Terminal is a terminal stored by the fake server.
+type Terminal struct {
+	ID            int
+	SerialNumber  string
+	Name          string
+	Status        string
+	ModelID       string
+	ClientID      string
+	TemplateID    int
+	FirmwareID    string
+	QueueFirmware bool
+	CloudAuthCode string
+	// Params holds the values overriding those of the template, by tag.
+	Params    map[string]string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Template is a template stored by the fake server.
+type Template struct {
+	ID       int
+	Name     string
+	ClientID string
+	// Applications are the IDs of the applications of the template.
+	Applications []string
+	Params       []amp360.Param
+}
+
+// Application is an application stored by the fake server, listed with the
+// templates naming it in their Applications.
+type Application struct {
+	ID       string
+	Name     string
+	Version  string
+	State    string
+	FileName string
+}
+
+// Firmware is a firmware stored by the fake server.
+type Firmware struct {
+	ID       string
+	Name     string
+	Version  string
+	ModelID  string
+	IsLatest bool
+}
+
+// Data seeds the store of the fake server.
+type Data struct {
+	Terminals    []Terminal
+	Templates    []Template
+	Applications []Application
+	Companies    []amp360.Company
+	Models       []amp360.TerminalModel
+	Firmware     []Firmware
+	// Files holds the content of the files served under BasePath, by path
+	// relative to it, e.g. "files/emv.xml".
+	Files map[string][]byte
+}
+
+// Faults configures the failures injected by the fake server.
+type Faults struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// FailNext makes the next FailNext requests fail with FailStatus.
+	FailNext int
+	// FailStatus is the status of injected failures, 503 if zero.
+	FailStatus int
+	// Unauthorized makes every request fail with 401.
+	Unauthorized bool
+}
+
+// Server is a stateful fake AMP360 API.
+type Server struct {
+	*httptest.Server
+
+	// TIDTag and MIDTag are the parameter tags matched by the tid and mid
+	// filters of the terminals list.
+	TIDTag string
+	MIDTag string
+
+	mu        sync.Mutex
+	terminals map[int]*Terminal
+	templates map[int]*Template
+	apps      map[string]*Application
+	companies []amp360.Company
+	models    []amp360.TerminalModel
+	firmware  map[string]*Firmware
+	files     map[string][]byte
+	nextID    int
+	apiKey    string
+	faults    Faults
+	requests  int
+}
+
+// NewServer starts a fake server with an empty store.
+func NewServer() *Server {
+	s := &Server{
+		TIDTag:    DefaultTIDTag,
+		MIDTag:    DefaultMIDTag,
+		terminals: map[int]*Terminal{},
+		templates: map[int]*Template{},
+		apps:      map[string]*Application{},
+		firmware:  map[string]*Firmware{},
+		files:     map[string][]byte{},
+		nextID:    1,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client talking to the fake server.
+func (s *Server) Client() *amp360.Client {
+	return amp360.NewClient(s.URL+BasePath, nil)
+}
+
+// Seed adds d to the store. Terminals without an ID get one assigned.
+func (s *Server) Seed(d Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range d.Templates {
+		t := d.Templates[i]
+		t.Params = append([]amp360.Param(nil), t.Params...)
+		s.templates[t.ID] = &t
+	}
+	for i := range d.Applications {
+		a := d.Applications[i]
+		s.apps[a.ID] = &a
+	}
+	for i := range d.Terminals {
+		t := d.Terminals[i]
+		s.addTerminal(&t)
+	}
+	s.companies = append(s.companies, d.Companies...)
+	s.models = append(s.models, d.Models...)
+	for i := range d.Firmware {
+		f := d.Firmware[i]
+		s.firmware[f.ID] = &f
+	}
+	for path, b := range d.Files {
+		s.files[path] = append([]byte(nil), b...)
+	}
+}
+
+func (s *Server) addTerminal(t *Terminal) {
+	if t.ID == 0 {
+		t.ID = s.nextID
+	}
+	if t.ID >= s.nextID {
+		s.nextID = t.ID + 1
+	}
+	if t.Params == nil {
+		t.Params = map[string]string{}
+	}
+	if t.Status == "" {
+		t.Status = "Pending download"
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC().Truncate(time.Second)
+		t.UpdatedAt = t.CreatedAt
+	}
+	s.terminals[t.ID] = t
+}
+
+// Terminal returns a copy of the stored terminal id.
+func (s *Server) Terminal(id int) (Terminal, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.terminals[id]
+	if !ok {
+		return Terminal{}, false
+	}
+	c := *t
+	c.Params = copyParams(t.Params)
+	return c, true
+}
+
+// Terminals returns copies of the stored terminals ordered by ID.
+func (s *Server) Terminals() []Terminal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Terminal{}
+	for _, id := range s.terminalIDs() {
+		t := *s.terminals[id]
+		t.Params = copyParams(t.Params)
+		out = append(out, t)
+	}
+	return out
+}
+
+// Template returns a copy of the stored template id.
+func (s *Server) Template(id int) (Template, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.templates[id]
+	if !ok {
+		return Template{}, false
+	}
+	c := *t
+	c.Applications = append([]string(nil), t.Applications...)
+	c.Params = append([]amp360.Param(nil), t.Params...)
+	return c, true
+}
+
+// Companies returns copies of the stored companies.
+func (s *Server) Companies() []amp360.Company {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]amp360.Company(nil), s.companies...)
+}
+
+// File returns the content of the file served at path, relative to
+// BasePath.
+func (s *Server) File(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.files[path]
+	return append([]byte(nil), b...), ok
+}
+
+// SetAPIKey makes the server reject requests not authorized with key.
+func (s *Server) SetAPIKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = key
+}
+
+// SetFaults sets the failures injected by the server.
+func (s *Server) SetFaults(f Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = f
+}
+
+// Requests returns the number of requests received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func copyParams(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (s *Server) terminalIDs() []int {
+	ids := make([]int, 0, len(s.terminals))
+	for id := range s.terminals {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) templateIDs() []int {
+	ids := make([]int, 0, len(s.templates))
+	for id := range s.templates {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+type response struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+type bulkResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Failed  []string `json:"failed"`
+	Updated []string `json:"updated"`
+}
+
+type list struct {
+	Count int         `json:"count"`
+	Rows  interface{} `json:"rows"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeData(w http.ResponseWriter, message string, data interface{}) {
+	writeJSON(w, http.StatusOK, response{Success: true, Message: message, Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, response{Message: message, Data: struct{}{}})
+}
+
+// paginate returns the requested page of n rows as a slice range.
+func paginate(q url.Values, n int) (int, int) {
+	size, _ := strconv.Atoi(q.Get("size"))
+	page, _ := strconv.Atoi(q.Get("page"))
+	if size <= 0 {
+		return 0, n
+	}
+	if page <= 0 {
+		page = 1
+	}
+	from, to := (page-1)*size, page*size
+	if from > n {
+		from = n
+	}
+	if to > n {
+		to = n
+	}
+	return from, to
+}
+
+// fault applies the configured faults, reporting whether the request was
+// answered.
+func (s *Server) fault(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	s.requests++
+	f := s.faults
+	if s.faults.FailNext > 0 {
+		s.faults.FailNext--
+	}
+	key := s.apiKey
+	s.mu.Unlock()
+
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if f.Unauthorized || (key != "" && r.Header.Get("Authorization") != key) {
+		writeError(w, http.StatusUnauthorized, "Authentication token validation error.")
+		return true
+	}
+	if f.FailNext > 0 {
+		status := f.FailStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, http.StatusText(status))
+		return true
+	}
+	return false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, BasePath) {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	if s.fault(w, r) {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, BasePath), "/")
+	seg := strings.Split(path, "/")
+	switch {
+	case path == "terminals" && r.Method == http.MethodGet:
+		s.listTerminals(w, r)
+	case path == "terminals" && r.Method == http.MethodPost:
+		s.createTerminal(w, r)
+	case path == "terminals/details" && r.Method == http.MethodGet:
+		s.terminalDetails(w, r)
+	case len(seg) == 2 && seg[0] == "terminals" && r.Method == http.MethodPut:
+		s.updateTerminal(w, r, seg[1])
+	case len(seg) == 2 && seg[0] == "terminals" && r.Method == http.MethodDelete:
+		s.deleteTerminal(w, r, seg[1])
+	case len(seg) == 3 && seg[0] == "terminals" && seg[1] == "params" && r.Method == http.MethodGet:
+		s.terminalParams(w, r, seg[2])
+	case len(seg) == 4 && seg[0] == "terminals" && seg[1] == "params" && seg[2] == "bulk" && r.Method == http.MethodPost:
+		s.updateTerminalParams(w, r, seg[3])
+	case path == "templates" && r.Method == http.MethodGet:
+		s.listTemplates(w, r)
+	case len(seg) == 3 && seg[0] == "templates" && seg[1] == "params" && r.Method == http.MethodGet:
+		s.templateParams(w, r, seg[2])
+	case len(seg) == 3 && seg[0] == "templates" && seg[1] == "params" && r.Method == http.MethodPost:
+		s.updateTemplateParams(w, r, seg[2])
+	case path == "client/children" && r.Method == http.MethodGet:
+		s.listCompanies(w, r)
+	case path == "models" && r.Method == http.MethodGet:
+		s.listModels(w, r)
+	case seg[0] == "files" && r.Method == http.MethodGet:
+		s.serveFile(w, path)
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+type terminalJSON struct {
+	ID              int       `json:"id"`
+	SerialNumber    string    `json:"serialNumber"`
+	Status          string    `json:"status"`
+	Name            string    `json:"name"`
+	CloudAuthCode   string    `json:"cloudAuthCode,omitempty"`
+	QueueFirmware   bool      `json:"queueFirmware"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	AppTemplateID   int       `json:"AppTemplateId"`
+	ClientID        string    `json:"ClientId"`
+	FirmwareID      string    `json:"FirmwareId"`
+	TerminalModelID string    `json:"TerminalModelId"`
+	AppTemplate     struct {
+		Name string `json:"name"`
+		ID   int    `json:"id"`
+	} `json:"AppTemplate"`
+}
+
+func (s *Server) terminalJSON(t *Terminal) terminalJSON {
+	j := terminalJSON{
+		ID:              t.ID,
+		SerialNumber:    t.SerialNumber,
+		Status:          t.Status,
+		Name:            t.Name,
+		CloudAuthCode:   t.CloudAuthCode,
+		QueueFirmware:   t.QueueFirmware,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+		AppTemplateID:   t.TemplateID,
+		ClientID:        t.ClientID,
+		FirmwareID:      t.FirmwareID,
+		TerminalModelID: t.ModelID,
+	}
+	j.AppTemplate.ID = t.TemplateID
+	if tmpl, ok := s.templates[t.TemplateID]; ok {
+		j.AppTemplate.Name = tmpl.Name
+	}
+	return j
+}
+
+// paramValue returns the value of tag for terminal t.
+func (s *Server) paramValue(t *Terminal, tag string) string {
+	if v, ok := t.Params[tag]; ok {
+		return v
+	}
+	if tmpl, ok := s.templates[t.TemplateID]; ok {
+		for _, p := range tmpl.Params {
+			if p.Tag == tag {
+				return p.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Server) matchTerminal(t *Terminal, q url.Values) bool {
+	if v := q.Get("id"); v != "" && v != strconv.Itoa(t.ID) {
+		return false
+	}
+	if v := q.Get("serialNumber"); v != "" && v != t.SerialNumber {
+		return false
+	}
+	if v := q.Get("tid"); v != "" && v != s.paramValue(t, s.TIDTag) {
+		return false
+	}
+	if v := q.Get("mid"); v != "" && v != s.paramValue(t, s.MIDTag) {
+		return false
+	}
+	return true
+}
+
+func (s *Server) listTerminals(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.URL.Query()
+	rows := []terminalJSON{}
+	for _, id := range s.terminalIDs() {
+		if t := s.terminals[id]; s.matchTerminal(t, q) {
+			rows = append(rows, s.terminalJSON(t))
+		}
+	}
+	from, to := paginate(q, len(rows))
+	writeData(w, "Successfully found the terminals.", list{Count: len(rows), Rows: rows[from:to]})
+}
+
+func (s *Server) createTerminal(w http.ResponseWriter, r *http.Request) {
+	nt := amp360.NewTerminal{}
+	if err := json.NewDecoder(r.Body).Decode(&nt); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if nt.SerialNumber == "" {
+		writeError(w, http.StatusBadRequest, "Serial number is required.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.terminals {
+		if t.SerialNumber == nt.SerialNumber {
+			writeError(w, http.StatusConflict, "Terminal with this serial number already exists.")
+			return
+		}
+	}
+
+	t := &Terminal{
+		SerialNumber:  nt.SerialNumber,
+		Name:          nt.Name,
+		ModelID:       fmt.Sprint(nt.ModelID),
+		CloudAuthCode: nt.CloudAuthCode,
+		Params:        map[string]string{},
+	}
+	if nt.ClientID != nil {
+		t.ClientID = fmt.Sprint(nt.ClientID)
+	}
+	if nt.TemplateID != "" {
+		id, err := strconv.Atoi(nt.TemplateID)
+		if _, ok := s.templates[id]; err != nil || !ok {
+			writeError(w, http.StatusBadRequest, "Template does not exist.")
+			return
+		}
+		t.TemplateID = id
+	}
+	for k, v := range nt.Parameters {
+		t.Params[k] = fmt.Sprint(v)
+	}
+	s.addTerminal(t)
+
+	// the create endpoint reports the template ID as a string
+	writeData(w, "Successfully created the terminal.", amp360.CreatedTerminal{
+		ID:              t.ID,
+		AppTemplateID:   nt.TemplateID,
+		ClientID:        t.ClientID,
+		FirmwareID:      t.FirmwareID,
+		TerminalModelID: t.ModelID,
+		SerialNumber:    t.SerialNumber,
+		Name:            t.Name,
+		Status:          t.Status,
+		CloudAuthCode:   t.CloudAuthCode,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	})
+}
+
+func (s *Server) lookupTerminal(w http.ResponseWriter, id string) (*Terminal, bool) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid terminal ID.")
+		return nil, false
+	}
+	t, ok := s.terminals[n]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Failed to find the terminal.")
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) updateTerminal(w http.ResponseWriter, r *http.Request, id string) {
+	// Like the real server, every field present in the body is written,
+	// blank or null ones included.
+	fields := map[string]json.RawMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTerminal(w, id)
+	if !ok {
+		return
+	}
+	for k, raw := range fields {
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		str := ""
+		if v != nil {
+			str = fmt.Sprint(v)
+		}
+		switch k {
+		case "name":
+			t.Name = str
+		case "serialNumber":
+			t.SerialNumber = str
+		case "modelId":
+			t.ModelID = str
+		case "clientId":
+			t.ClientID = str
+		case "templateId":
+			n, _ := strconv.Atoi(str)
+			t.TemplateID = n
+		}
+	}
+	t.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	writeData(w, "Successfully updated the terminal.", struct{}{})
+}
+
+func (s *Server) deleteTerminal(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTerminal(w, id)
+	if !ok {
+		return
+	}
+	delete(s.terminals, t.ID)
+	writeData(w, "Successfully deleted the terminal.", struct{}{})
+}
+
+func categories(params []amp360.Param) []amp360.Categories {
+	seen := map[string]bool{}
+	out := []amp360.Categories{}
+	for _, p := range params {
+		if !seen[p.ParamCategoryID] {
+			seen[p.ParamCategoryID] = true
+			out = append(out, amp360.Categories{ID: p.ParamCategoryID, Name: p.CategoryName})
+		}
+	}
+	return out
+}
+
+func (s *Server) terminalParams(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTerminal(w, id)
+	if !ok {
+		return
+	}
+
+	var tparams []amp360.Param
+	if tmpl, ok := s.templates[t.TemplateID]; ok {
+		tparams = tmpl.Params
+	}
+	category := r.URL.Query().Get("categoryId")
+	rows := []amp360.Parameter{}
+	for i, p := range tparams {
+		if category != "" && p.ParamCategoryID != category {
+			continue
+		}
+		rows = append(rows, amp360.Parameter{
+			ID:                t.ID*10000 + i,
+			Type:              p.Type,
+			Tag:               p.Tag,
+			Name:              p.Name,
+			Hint:              p.Hint,
+			Validator:         p.Validator,
+			Value:             s.paramValue(t, p.Tag),
+			DefaultValue:      p.DefaultValue,
+			VisibleOnTemplate: p.VisibleOnTemplate,
+			VisibleOnTerminal: p.VisibleOnTerminal,
+			ApplicationID:     p.ApplicationID,
+			ParamCategoryID:   p.ParamCategoryID,
+			CategoryName:      p.CategoryName,
+		})
+	}
+	writeData(w, "Successfully found the terminal parameters.", amp360.TerminalParams{
+		Categories: categories(tparams),
+		Count:      len(rows),
+		Rows:       rows,
+	})
+}
+
+// readBulk returns the values of a multipart bulk request and the content
+// of its files, by parameter. Uploaded files are stored as their file name.
+func readBulk(r *http.Request) (map[string]string, map[string][]byte, error) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, nil, err
+	}
+	values := map[string]string{}
+	for k, v := range r.MultipartForm.Value {
+		values[k] = v[0]
+	}
+	files := map[string][]byte{}
+	for k, fh := range r.MultipartForm.File {
+		f, err := fh[0].Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		values[k] = fh[0].Filename
+		files[k] = b
+	}
+	return values, files, nil
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, path string) {
+	s.mu.Lock()
+	b, ok := s.files[path]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Failed to find the file.")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(b)
+}
+
+func writeBulk(w http.ResponseWriter, updated, failed []string) {
+	sort.Strings(updated)
+	sort.Strings(failed)
+	writeJSON(w, http.StatusOK, bulkResponse{
+		Success: len(updated) > 0,
+		Message: fmt.Sprintf("Successfully updated %d parameter(s).", len(updated)),
+		Updated: updated,
+		Failed:  failed,
+	})
+}
+
+func (s *Server) updateTerminalParams(w http.ResponseWriter, r *http.Request, id string) {
+	values, _, err := readBulk(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTerminal(w, id)
+	if !ok {
+		return
+	}
+
+	known := map[string]bool{}
+	if tmpl, ok := s.templates[t.TemplateID]; ok {
+		for _, p := range tmpl.Params {
+			known[p.Tag] = true
+		}
+	}
+	updated, failed := []string{}, []string{}
+	for k, v := range values {
+		if !known[k] {
+			failed = append(failed, k)
+			continue
+		}
+		t.Params[k] = v
+		updated = append(updated, k)
+	}
+	writeBulk(w, updated, failed)
+}
+
+type templateJSON struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ClientID string `json:"ClientId"`
+	Client   struct {
+		ID string `json:"id"`
+	} `json:"Client"`
+	Applications []amp360.AppTemplate `json:"Applications"`
+}
+
+func (s *Server) listTemplates(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := []templateJSON{}
+	for _, id := range s.templateIDs() {
+		t := s.templates[id]
+		j := templateJSON{ID: t.ID, Name: t.Name, ClientID: t.ClientID, Applications: []amp360.AppTemplate{}}
+		j.Client.ID = t.ClientID
+		for _, appID := range t.Applications {
+			app := amp360.AppTemplate{ID: appID}
+			if a, ok := s.apps[appID]; ok {
+				app.Name, app.Version, app.State, app.FileName = a.Name, a.Version, a.State, a.FileName
+			}
+			j.Applications = append(j.Applications, app)
+		}
+		rows = append(rows, j)
+	}
+	from, to := paginate(r.URL.Query(), len(rows))
+	writeData(w, "Successfully found the client's templates.", list{Count: len(rows), Rows: rows[from:to]})
+}
+
+func (s *Server) lookupTemplate(w http.ResponseWriter, id string) (*Template, bool) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid template ID.")
+		return nil, false
+	}
+	t, ok := s.templates[n]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Failed to find the template.")
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) templateParams(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTemplate(w, id)
+	if !ok {
+		return
+	}
+	category := r.URL.Query().Get("categoryId")
+	rows := []amp360.Param{}
+	for _, p := range t.Params {
+		if category == "" || p.ParamCategoryID == category {
+			rows = append(rows, p)
+		}
+	}
+	writeData(w, "Successfully found the template parameters.", amp360.TemplateParams{
+		Categories: categories(t.Params),
+		Count:      len(rows),
+		Rows:       rows,
+	})
+}
+
+func (s *Server) updateTemplateParams(w http.ResponseWriter, r *http.Request, id string) {
+	values, files, err := readBulk(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTemplate(w, id)
+	if !ok {
+		return
+	}
+	updated, failed := []string{}, []string{}
+	for k, v := range values {
+		found := false
+		for i := range t.Params {
+			if t.Params[i].Tag == k {
+				t.Params[i].Value = v
+				if b, ok := files[k]; ok {
+					t.Params[i].FilePath = fmt.Sprintf("files/templates/%d/%s", t.ID, v)
+					s.files[t.Params[i].FilePath] = b
+				}
+				t.Params[i].UpdatedAt = time.Now().UTC().Truncate(time.Second)
+				found = true
+			}
+		}
+		if found {
+			updated = append(updated, k)
+		} else {
+			failed = append(failed, k)
+		}
+	}
+	writeBulk(w, updated, failed)
+}
+
+func (s *Server) listCompanies(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parent := r.URL.Query().Get("parentId")
+	if parent != "" && s.companyIndex(parent) < 0 {
+		writeError(w, http.StatusNotFound, "Failed to find the client.")
+		return
+	}
+	rows := []amp360.Company{}
+	for _, c := range s.companies {
+		if c.ParentID == parent {
+			rows = append(rows, c)
+		}
+	}
+	from, to := paginate(r.URL.Query(), len(rows))
+	writeData(w, "Successfully fetched sub-clients.", list{Count: len(rows), Rows: rows[from:to]})
+}
+
+// companyIndex returns the index of company id, -1 if there is none.
+func (s *Server) companyIndex(id string) int {
+	for i, c := range s.companies {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) listModels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := append([]amp360.TerminalModel{}, s.models...)
+	writeData(w, "Successfully found available terminal models.", list{Count: len(rows), Rows: rows})
+}
+
+func (s *Server) terminalDetails(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.URL.Query()
+	var t *Terminal
+	for _, id := range s.terminalIDs() {
+		if s.matchTerminal(s.terminals[id], q) {
+			t = s.terminals[id]
+			break
+		}
+	}
+	if t == nil {
+		writeError(w, http.StatusNotFound, "Failed to find the terminal.")
+		return
+	}
+
+	d := amp360.Details{}
+	d.Terminal.ID = t.ID
+	d.Terminal.SerialNumber = t.SerialNumber
+	d.Terminal.Status = t.Status
+	d.Terminal.Name = t.Name
+	d.Terminal.CloudAuthCode = t.CloudAuthCode
+	if t.QueueFirmware {
+		d.Terminal.QueueFirmware = 1
+	}
+	d.Terminal.CreatedAt = t.CreatedAt
+	d.Terminal.UpdatedAt = t.UpdatedAt
+	d.Terminal.AppTemplateID = t.TemplateID
+	d.Terminal.ClientID = t.ClientID
+	d.Terminal.FirmwareID = t.FirmwareID
+	d.Terminal.TerminalModelID = t.ModelID
+	if f, ok := s.firmware[t.FirmwareID]; ok {
+		d.Terminal.Firmware.ID = f.ID
+		d.Terminal.Firmware.Name = f.Name
+		d.Terminal.Firmware.Version = f.Version
+		if f.IsLatest {
+			d.Terminal.Firmware.IsLatest = 1
+		}
+	}
+	for _, m := range s.models {
+		if m.ID == t.ModelID {
+			d.Terminal.TerminalModel.ID = m.ID
+			d.Terminal.TerminalModel.Name = m.Name
+			d.Terminal.TerminalModel.HardwareID = m.HardwareID
+		}
+	}
+	writeData(w, "Successfully found the terminal details.", d)
+}
@@ -0,0 +1,256 @@
+package amp360test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+func seeded(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(Data{
+		Templates: []Template{{
+			ID:   814,
+			Name: "APITEST",
+			Params: []amp360.Param{
+				{Tag: DefaultMIDTag, Value: "000000000", DefaultValue: "000000000", ParamCategoryID: "c1", CategoryName: "TERMINAL"},
+				{Tag: DefaultTIDTag, Value: "00000000", DefaultValue: "00000000", ParamCategoryID: "c1", CategoryName: "TERMINAL"},
+				{Tag: "COMMUNICATIONS.MEDIA.PRIMARY", Value: "CELLULAR", ParamCategoryID: "c2", CategoryName: "Communications"},
+			},
+		}},
+		Terminals: []Terminal{
+			{ID: 25, SerialNumber: "8000044499", Name: "T1", TemplateID: 814, ModelID: "m1", FirmwareID: "f1", Params: map[string]string{DefaultTIDTag: "12345678"}},
+		},
+		Companies: []amp360.Company{{ID: "test1", Name: "TEST 1", Type: "MERCHANT"}},
+		Models:    []amp360.TerminalModel{{ID: "m1", Name: "AMP8000", HardwareID: "2AA"}},
+		Firmware:  []Firmware{{ID: "f1", Name: "AMP8000-2AA", Version: "03.02.39", ModelID: "m1", IsLatest: true}},
+	})
+	return srv
+}
+
+func TestTerminalsCRUD(t *testing.T) {
+	srv := seeded(t)
+	c := srv.Client()
+	ctx := context.Background()
+
+	ct, err := c.TerminalsService.Add(ctx, &amp360.NewTerminal{
+		ModelID:      "m1",
+		SerialNumber: "8000000001",
+		Name:         "T2",
+		TemplateID:   "814",
+		Parameters:   map[string]interface{}{DefaultMIDTag: "400081203"},
+	})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+
+	_, err = c.TerminalsService.Add(ctx, &amp360.NewTerminal{SerialNumber: "8000000001"})
+	if !errors.Is(err, amp360.ErrConflict) {
+		t.Errorf("Error got %v, want %v", err, amp360.ErrConflict)
+	}
+
+	tl, err := c.TerminalsService.List(ctx, &amp360.TerminalsOpt{MID: "400081203"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tl.Count != 1 || tl.Rows[0].ID != ct.ID {
+		t.Errorf("Terminals got %+v, want terminal %d", tl, ct.ID)
+	}
+
+	before, _ := srv.Terminal(ct.ID)
+	if err := c.TerminalsService.Edit(ctx, ct.ID, &amp360.TerminalUpdate{Name: "renamed"}); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	got, _ := srv.Terminal(ct.ID)
+	if got.Name != "renamed" {
+		t.Errorf("Name got %v, want %v", got.Name, "renamed")
+	}
+	if got.SerialNumber != before.SerialNumber || got.ModelID != before.ModelID {
+		t.Errorf("Edit changed serial %q, model %q", got.SerialNumber, got.ModelID)
+	}
+
+	if err := c.TerminalsService.Delete(ctx, ct.ID); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	err = c.TerminalsService.Delete(ctx, ct.ID)
+	if !errors.Is(err, amp360.ErrEntityNotFound) {
+		t.Errorf("Error got %v, want %v", err, amp360.ErrEntityNotFound)
+	}
+	if n := len(srv.Terminals()); n != 1 {
+		t.Errorf("Terminals count got %v, want %v", n, 1)
+	}
+}
+
+func TestParams(t *testing.T) {
+	srv := seeded(t)
+	c := srv.Client()
+	ctx := context.Background()
+
+	tp, err := c.TerminalsService.Params(ctx, 25, &amp360.ParamsOpt{CategoryId: "c1"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tp.Count != 2 || tp.Rows[1].Value != "12345678" {
+		t.Errorf("Terminal params got %+v", tp)
+	}
+
+	res, err := c.TerminalsService.SetParams(ctx, 25, map[string]string{DefaultMIDTag: "1", "UNKNOWN": "x"}, map[string]string{"COMMUNICATIONS.MEDIA.PRIMARY": "server.go"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(res.Updated) != 2 || len(res.Failed) != 1 || res.Failed[0] != "UNKNOWN" {
+		t.Errorf("Bulk result got %+v", res)
+	}
+	if got, _ := srv.Terminal(25); got.Params["COMMUNICATIONS.MEDIA.PRIMARY"] != "server.go" {
+		t.Errorf("file param got %v, want %v", got.Params["COMMUNICATIONS.MEDIA.PRIMARY"], "server.go")
+	}
+
+	if _, err := c.TemplatesService.SetParams(ctx, 814, map[string]string{DefaultMIDTag: "2"}, nil); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	tmpl, err := c.TemplatesService.Params(ctx, 814, nil)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tmpl.Rows[0].Value != "2" || len(tmpl.Categories) != 2 {
+		t.Errorf("Template params got %+v", tmpl)
+	}
+}
+
+func TestApplications(t *testing.T) {
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(Data{
+		Applications: []Application{
+			{ID: "a1", Name: "POS", Version: "1.0.0"},
+			{ID: "a2", Name: "POS", Version: "1.1.0"},
+		},
+		Templates: []Template{
+			{ID: 1, Name: "A", Applications: []string{"a1"}},
+			{ID: 2, Name: "B", Applications: []string{"a2"}},
+		},
+	})
+	c := srv.Client()
+
+	old, err := c.ApplicationsService.TemplatesRunning(context.Background(), "POS", "1.0.0")
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(old) != 1 || old[0].ID != 1 {
+		t.Errorf("Templates running 1.0.0 got %+v", old)
+	}
+}
+
+func TestFirmware(t *testing.T) {
+	srv := seeded(t)
+	srv.Seed(Data{
+		Firmware:  []Firmware{{ID: "f0", Name: "AMP8000-2AA", Version: "03.02.38", ModelID: "m1"}},
+		Terminals: []Terminal{{ID: 26, SerialNumber: "8000044500", ModelID: "m1", FirmwareID: "f0", QueueFirmware: true}},
+	})
+	c := srv.Client()
+
+	r, err := c.FirmwareService.Compliance(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if r.Terminals != 2 || r.Outdated != 1 || len(r.Errors) != 0 {
+		t.Fatalf("Compliance report got %+v", r)
+	}
+	outdated := r.OutdatedGroups()
+	if len(outdated) != 1 || outdated[0].FirmwareID != "f0" || outdated[0].Terminals[0].ID != 26 || !outdated[0].Terminals[0].Queued {
+		t.Errorf("Outdated groups got %+v", outdated)
+	}
+}
+
+func TestCompanies(t *testing.T) {
+	srv := seeded(t)
+	srv.Seed(Data{Companies: []amp360.Company{
+		{ID: "r1", Name: "RESELLER"},
+		{ID: "s1", Name: "SUB", ParentID: "r1"},
+		{ID: "m1", Name: "SHOP", ParentID: "s1", OriginPath: "reseller/sub/shop"},
+		{ID: "o1", Name: "OUTLET", ParentID: "m1"},
+	}})
+	c := srv.Client()
+	ctx := context.Background()
+
+	tree, err := c.CompaniesService.Tree(ctx, nil)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tree.Len() != 5 || len(tree.Roots) != 2 {
+		t.Errorf("Tree got %d companies, %d roots", tree.Len(), len(tree.Roots))
+	}
+	outlet, err := tree.Resolve("RESELLER/SUB/SHOP/OUTLET")
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if outlet.ID != "o1" || outlet.Depth() != 4 {
+		t.Errorf("Resolved company got %+v", outlet)
+	}
+	if shop, err := c.CompaniesService.Resolve(ctx, "reseller/sub/shop"); err != nil || shop.ID != "m1" {
+		t.Errorf("Resolve by origin path got %+v, %v", shop, err)
+	}
+}
+func TestReferenceData(t *testing.T) {
+	srv := seeded(t)
+	c := srv.Client()
+	ctx := context.Background()
+
+	cl, err := c.CompaniesService.List(ctx, nil)
+	if err != nil || cl.Count != 1 {
+		t.Errorf("Companies got %+v, error %v", cl, err)
+	}
+	ml, err := c.ModelsService.List(ctx)
+	if err != nil || ml.Count != 1 {
+		t.Errorf("Models got %+v, error %v", ml, err)
+	}
+	tl, err := c.TemplatesService.List(ctx, nil)
+	if err != nil || tl.Count != 1 || tl.Rows[0].Name != "APITEST" {
+		t.Errorf("Templates got %+v, error %v", tl, err)
+	}
+	d, err := c.TerminalsService.Details(ctx, &amp360.TerminalsOpt{SerialNumber: "8000044499"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if d.Terminal.ID != 25 || d.Terminal.Firmware.IsLatest != 1 || d.Terminal.TerminalModel.Name != "AMP8000" {
+		t.Errorf("Details got %+v", d.Terminal)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	srv := seeded(t)
+	c := srv.Client()
+	ctx := context.Background()
+
+	srv.SetFaults(Faults{FailNext: 1, FailStatus: http.StatusBadGateway})
+	_, err := c.ModelsService.List(ctx)
+	var apiErr *amp360.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Error got %v, want 502", err)
+	}
+	if _, err := c.ModelsService.List(ctx); err != nil {
+		t.Errorf("Error occured = %v", err)
+	}
+
+	srv.SetAPIKey("key")
+	if _, err := c.ModelsService.List(ctx); !errors.Is(err, amp360.ErrIvalidToken) {
+		t.Errorf("Error got %v, want %v", err, amp360.ErrIvalidToken)
+	}
+	c.SetAPIKey("key")
+	if _, err := c.ModelsService.List(ctx); err != nil {
+		t.Errorf("Error occured = %v", err)
+	}
+
+	srv.SetFaults(Faults{Latency: 50 * time.Millisecond})
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.ModelsService.List(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Error got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,42 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+)
+
+// ApplicationsService reports the applications run by the templates of the
+// client.
+type ApplicationsService service
+
+// TemplatesRunning returns the templates with version version of
+// application name.
+func (c *ApplicationsService) TemplatesRunning(ctx context.Context, name, version string) ([]Template, error) {
+	usage, err := c.Usage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return usage[version], nil
+}
+
+// Usage returns the templates with application name, by version. It lists
+// all the templates of the client.
+func (c *ApplicationsService) Usage(ctx context.Context, name string) (map[string][]Template, error) {
+	if name == "" {
+		return nil, errors.New("required application name is missing")
+	}
+	usage := map[string][]Template{}
+	it := c.client.TemplatesService.All(ctx, nil)
+	for it.Next() {
+		t := it.Value()
+		for _, app := range t.Applications {
+			if app.Name == name {
+				usage[app.Version] = append(usage[app.Version], t)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
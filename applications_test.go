@@ -0,0 +1,40 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestApplicationsUsageMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/templates", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"count":3,"rows":[
+			{"id":1,"name":"A","Applications":[{"id":"a1","name":"POS","version":"1.0.0"}]},
+			{"id":2,"name":"B","Applications":[{"id":"a2","name":"POS","version":"1.1.0"},{"id":"a3","name":"POS LITE","version":"1.0.0"}]},
+			{"id":3,"name":"C","Applications":[{"id":"a1","name":"POS","version":"1.0.0"}]}]}}`)
+	})
+
+	ctx := context.Background()
+	usage, err := c.ApplicationsService.Usage(ctx, "POS")
+	if err != nil {
+		t.Fatalf("Usage returned error: %v", err)
+	}
+	if len(usage) != 2 || len(usage["1.0.0"]) != 2 || len(usage["1.1.0"]) != 1 {
+		t.Errorf("Usage = %+v", usage)
+	}
+
+	old, err := c.ApplicationsService.TemplatesRunning(ctx, "POS", "1.0.0")
+	if err != nil {
+		t.Fatalf("TemplatesRunning returned error: %v", err)
+	}
+	if len(old) != 2 || old[0].ID != 1 || old[1].ID != 3 {
+		t.Errorf("TemplatesRunning = %+v", old)
+	}
+	if none, _ := c.ApplicationsService.TemplatesRunning(ctx, "POS", "0.9.0"); len(none) != 0 {
+		t.Errorf("TemplatesRunning of an unused version = %+v", none)
+	}
+}
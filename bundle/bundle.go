@@ -0,0 +1,222 @@
+// Package bundle moves the parameters of a template between templates and
+// environments as a versioned YAML or JSON file. The files of file
+// parameters are kept in a sidecar directory next to it.
+//
+//	b, err := bundle.Export(ctx, dev, 814, "pos.yaml") // writes pos.files/ too
+//	b, err = bundle.ReadFile("pos.yaml")
+//	res, err := b.Import(ctx, prod, 902, true) // dry run
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrei-cloud/amp360"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the bundles written by this package.
+const SchemaVersion = 1
+
+// Bundle is the exported parameter set of a template.
+type Bundle struct {
+	SchemaVersion int                 `json:"schemaVersion" yaml:"schemaVersion"`
+	TemplateID    int                 `json:"templateId" yaml:"templateId"`
+	Source        string              `json:"source,omitempty" yaml:"source,omitempty"` // base URL exported from
+	ExportedAt    time.Time           `json:"exportedAt" yaml:"exportedAt"`
+	Categories    []amp360.Categories `json:"categories" yaml:"categories"`
+	Params        []Param             `json:"params" yaml:"params"`
+
+	dir string // directory the file paths are relative to
+}
+
+// Param is an exported parameter.
+type Param struct {
+	Tag          string `json:"tag" yaml:"tag"`
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+	Type         string `json:"type,omitempty" yaml:"type,omitempty"`
+	Category     string `json:"category,omitempty" yaml:"category,omitempty"`
+	Value        string `json:"value" yaml:"value"`
+	DefaultValue string `json:"defaultValue,omitempty" yaml:"defaultValue,omitempty"`
+	// File is the path of the file of a file parameter, relative to the
+	// bundle.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// Export writes the parameters of template id to a bundle at path,
+// in JSON if path ends in .json and YAML otherwise. The files of file
+// parameters are downloaded to the sidecar directory FilesDir(path).
+func Export(ctx context.Context, c *amp360.Client, id int, path string) (*Bundle, error) {
+	tp, err := c.TemplatesService.Params(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{
+		SchemaVersion: SchemaVersion,
+		TemplateID:    id,
+		Source:        c.BaseURL.String(),
+		ExportedAt:    time.Now().UTC().Truncate(time.Second),
+		Categories:    tp.Categories,
+		Params:        make([]Param, 0, len(tp.Rows)),
+		dir:           filepath.Dir(path),
+	}
+
+	sidecar := FilesDir(path)
+	for _, p := range tp.Rows {
+		bp := Param{
+			Tag:          p.Tag,
+			Name:         p.Name,
+			Type:         p.Type,
+			Category:     p.CategoryName,
+			Value:        p.Value,
+			DefaultValue: p.DefaultValue,
+		}
+		if p.FilePath != "" {
+			name := filepath.Join(sidecar, p.Tag, remoteName(p.FilePath))
+			if err := download(ctx, c, p.FilePath, name); err != nil {
+				return nil, fmt.Errorf("bundle: %s: %w", p.Tag, err)
+			}
+			rel, err := filepath.Rel(b.dir, name)
+			if err != nil {
+				return nil, err
+			}
+			bp.File = filepath.ToSlash(rel)
+		}
+		b.Params = append(b.Params, bp)
+	}
+	return b, b.WriteFile(path)
+}
+
+// FilesDir returns the sidecar directory of the bundle at path: path
+// without its extension, followed by ".files".
+func FilesDir(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".files"
+}
+
+// remoteName returns the file name of a remote file path.
+func remoteName(filePath string) string {
+	if i := strings.IndexAny(filePath, "?#"); i >= 0 {
+		filePath = filePath[:i]
+	}
+	return path.Base(filePath)
+}
+
+func download(ctx context.Context, c *amp360.Client, filePath, name string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Download(ctx, filePath, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteFile writes b to path, in JSON if path ends in .json and YAML
+// otherwise. File paths stay relative to the directory b was read from or
+// exported to.
+func (b *Bundle) WriteFile(path string) error {
+	buf := &bytes.Buffer{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(b); err != nil {
+			return err
+		}
+	} else {
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(b); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// ReadFile reads the bundle at path. File paths are resolved relative to the
+// directory of path.
+func ReadFile(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	b.dir = filepath.Dir(path)
+	return b, nil
+}
+
+// Decode reads a bundle in YAML or JSON. Bundles of any schema version up to
+// SchemaVersion are accepted. File paths are resolved relative to the
+// working directory.
+func Decode(r io.Reader) (*Bundle, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var head struct {
+		SchemaVersion int `yaml:"schemaVersion"`
+	}
+	if err := yaml.Unmarshal(raw, &head); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+
+	b := &Bundle{}
+	switch {
+	case head.SchemaVersion == 0:
+		return nil, errors.New("bundle: missing schema version")
+	case head.SchemaVersion > SchemaVersion:
+		return nil, fmt.Errorf("bundle: schema version %d is newer than the supported version %d", head.SchemaVersion, SchemaVersion)
+	case head.SchemaVersion == 1:
+		// Older versions get their own case, decoding into their own
+		// types and converting to the current ones.
+		if err := yaml.Unmarshal(raw, b); err != nil {
+			return nil, fmt.Errorf("bundle: %w", err)
+		}
+	}
+	for _, p := range b.Params {
+		if err := checkFile(p); err != nil {
+			return nil, err
+		}
+	}
+	b.SchemaVersion = SchemaVersion
+	return b, nil
+}
+
+// path returns the path of the file of p, which must stay inside the
+// directory of the bundle.
+func (b *Bundle) path(p Param) (string, error) {
+	if err := checkFile(p); err != nil {
+		return "", err
+	}
+	return filepath.Join(b.dir, filepath.FromSlash(p.File)), nil
+}
+
+// checkFile checks that the file of p is a relative path that doesn't leave
+// the directory of the bundle, so that a crafted bundle can't make Import
+// upload any local file.
+func checkFile(p Param) error {
+	if p.File == "" || filepath.IsLocal(filepath.FromSlash(p.File)) {
+		return nil
+	}
+	return fmt.Errorf("bundle: file %q of parameter %s is outside the bundle directory", p.File, p.Tag)
+}
@@ -0,0 +1,146 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+)
+
+func newServer(t *testing.T) *amp360test.Server {
+	t.Helper()
+	srv := amp360test.NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{
+			{ID: 814, Params: []amp360.Param{
+				{Tag: "HOST", CategoryName: "COMMS", Value: "10.0.0.1"},
+				{Tag: "EMV", CategoryName: "EMV", Type: "FILE", Value: "emv.xml", FilePath: "files/templates/814/emv.xml"},
+				{Tag: "LOGO", CategoryName: "UI", Value: "logo"},
+			}},
+			{ID: 902, Params: []amp360.Param{
+				{Tag: "HOST", CategoryName: "COMMS", Value: "10.0.0.2"},
+				{Tag: "EMV", CategoryName: "EMV", Type: "FILE", Value: "emv.xml", FilePath: "files/templates/902/emv.xml"},
+			}},
+		},
+		Files: map[string][]byte{
+			"files/templates/814/emv.xml": []byte("<emv>tuned</emv>"),
+			"files/templates/902/emv.xml": []byte("<emv/>"),
+		},
+	})
+	return srv
+}
+
+func TestExportImport(t *testing.T) {
+	srv := newServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pos.yaml")
+
+	if _, err := Export(ctx, c, 814, path); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(FilesDir(path), "EMV", "emv.xml"))
+	if err != nil || string(b) != "<emv>tuned</emv>" {
+		t.Fatalf("sidecar file got %q, error %v", b, err)
+	}
+
+	bundle, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if bundle.SchemaVersion != SchemaVersion || len(bundle.Params) != 3 || bundle.Params[1].File != "pos.files/EMV/emv.xml" {
+		t.Fatalf("bundle got %+v", bundle)
+	}
+
+	res, err := bundle.Import(ctx, c, 902, true)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	want := []Change{
+		{Tag: "HOST", Category: "COMMS", From: "10.0.0.2", To: "10.0.0.1"},
+		{Tag: "EMV", Category: "EMV", From: "emv.xml", To: "emv.xml", File: true},
+		{Tag: "LOGO", Category: "UI", To: "logo", Missing: true},
+	}
+	if len(res.Changes) != len(want) {
+		t.Fatalf("changes got %+v, want %+v", res.Changes, want)
+	}
+	for i := range want {
+		if res.Changes[i] != want[i] {
+			t.Errorf("change %d got %+v, want %+v", i, res.Changes[i], want[i])
+		}
+	}
+	if tmpl, _ := srv.Template(902); tmpl.Params[0].Value != "10.0.0.2" {
+		t.Error("dry run updated the template")
+	}
+
+	res, err = bundle.Import(ctx, c, 902, false)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if strings.Join(res.Updated, ",") != "EMV,HOST" || len(res.Failed) != 0 {
+		t.Errorf("result got %+v", res)
+	}
+	if b, _ := srv.File("files/templates/902/emv.xml"); string(b) != "<emv>tuned</emv>" {
+		t.Errorf("imported file got %q", b)
+	}
+
+	changes, err := bundle.Diff(ctx, c, 902)
+	if err != nil || len(changes) != 1 || !changes[0].Missing {
+		t.Errorf("changes after import got %+v, error %v", changes, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	b, err := Decode(strings.NewReader(`{"schemaVersion":1,"templateId":814,"exportedAt":"2022-03-01T10:00:00Z","params":[{"tag":"HOST","value":"x"}],"addedLater":true}`))
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if b.TemplateID != 814 || b.Params[0].Value != "x" || b.ExportedAt.Year() != 2022 {
+		t.Errorf("bundle got %+v", b)
+	}
+
+	for _, doc := range []string{"templateId: 814\n", "schemaVersion: 99\n"} {
+		if _, err := Decode(strings.NewReader(doc)); err == nil {
+			t.Errorf("Error is nil for %q", doc)
+		}
+	}
+}
+
+func TestWriteFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pos.json")
+	b := &Bundle{SchemaVersion: SchemaVersion, TemplateID: 814, Params: []Param{{Tag: "HOST", Value: "x"}}}
+	if err := b.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(path)
+	if err != nil || got.Params[0].Tag != "HOST" {
+		t.Errorf("bundle got %+v, error %v", got, err)
+	}
+}
+
+func TestFileOutsideBundle(t *testing.T) {
+	for _, file := range []string{"../../.ssh/id_rsa", "files/../../x", "/etc/passwd"} {
+		doc := fmt.Sprintf("schemaVersion: 1\ntemplateId: 814\nparams:\n  - tag: LOGO\n    file: %s\n", file)
+		if _, err := Decode(strings.NewReader(doc)); err == nil {
+			t.Errorf("Decode accepted file %q", file)
+		}
+		b := &Bundle{dir: t.TempDir(), Params: []Param{{Tag: "LOGO", File: file}}}
+		if _, err := b.path(b.Params[0]); err == nil {
+			t.Errorf("path accepted file %q", file)
+		}
+	}
+
+	b, err := Decode(strings.NewReader("schemaVersion: 1\nparams:\n  - tag: LOGO\n    file: files/./logo.bmp\n"))
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if _, err := b.path(b.Params[0]); err != nil {
+		t.Errorf("path rejected a file inside the bundle: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+// Change is a parameter of a target template changed by an import. For file
+// parameters From and To are file names.
+type Change struct {
+	Tag      string `json:"tag"`
+	Category string `json:"category,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	File     bool   `json:"file,omitempty"`
+	// Missing is set for the parameters the target template doesn't have,
+	// which are not imported.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	Changes []Change `json:"changes"`
+	Updated []string `json:"updated,omitempty"`
+	Failed  []string `json:"failed,omitempty"`
+	DryRun  bool     `json:"dryRun,omitempty"`
+}
+
+// Diff compares b with the parameters of template id and returns
+// the changes an import would make, in the order of the bundle. Files are
+// compared by content.
+func (b *Bundle) Diff(ctx context.Context, c *amp360.Client, id int) ([]Change, error) {
+	tp, err := c.TemplatesService.Params(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	target := make(map[string]amp360.Param, len(tp.Rows))
+	for _, p := range tp.Rows {
+		target[p.Tag] = p
+	}
+
+	changes := []Change{}
+	for _, p := range b.Params {
+		cur, ok := target[p.Tag]
+		ch := Change{Tag: p.Tag, Category: p.Category, To: p.Value, Missing: !ok}
+		if !ok {
+			changes = append(changes, ch)
+			continue
+		}
+		if p.File == "" {
+			if cur.Value != p.Value {
+				ch.From = cur.Value
+				changes = append(changes, ch)
+			}
+			continue
+		}
+
+		ch.File, ch.To = true, filepath.Base(p.File)
+		if cur.FilePath != "" {
+			ch.From = remoteName(cur.FilePath)
+		}
+		same, err := b.sameFile(ctx, c, p, cur.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changes = append(changes, ch)
+		}
+	}
+	return changes, nil
+}
+
+// sameFile reports whether the file of p has the content of the remote file
+// at filePath.
+func (b *Bundle) sameFile(ctx context.Context, c *amp360.Client, p Param, filePath string) (bool, error) {
+	if filePath == "" || remoteName(filePath) != filepath.Base(p.File) {
+		return false, nil
+	}
+	name, err := b.path(p)
+	if err != nil {
+		return false, err
+	}
+	local, err := os.ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+	remote := &bytes.Buffer{}
+	if _, err := c.Download(ctx, filePath, remote); err != nil {
+		return false, err
+	}
+	return bytes.Equal(local, remote.Bytes()), nil
+}
+
+// Import applies the changes of b to template id in one bulk
+// update. With dryRun set, the changes are only computed.
+func (b *Bundle) Import(ctx context.Context, c *amp360.Client, id int, dryRun bool) (*Result, error) {
+	changes, err := b.Diff(ctx, c, id)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Changes: changes, DryRun: dryRun}
+	if dryRun {
+		return res, nil
+	}
+
+	files := map[string]Param{}
+	for _, p := range b.Params {
+		if p.File != "" {
+			files[p.Tag] = p
+		}
+	}
+	up := &amp360.ParamsUpload{Params: map[string]string{}}
+	for _, ch := range changes {
+		switch {
+		case ch.Missing:
+			// the server would fail it
+		case ch.File:
+			name, err := b.path(files[ch.Tag])
+			if err != nil {
+				return nil, err
+			}
+			up.Files = append(up.Files, amp360.ParamFile{Param: ch.Tag, Path: name})
+		default:
+			up.Params[ch.Tag] = ch.To
+		}
+	}
+	if len(up.Params) == 0 && len(up.Files) == 0 {
+		return res, nil
+	}
+
+	br, err := c.TemplatesService.UploadParams(ctx, id, up)
+	if err != nil {
+		return nil, err
+	}
+	res.Updated, res.Failed = br.Updated, br.Failed
+	return res, nil
+}
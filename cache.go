@@ -0,0 +1,196 @@
+package amp360
+
+import (
+	"container/list"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a cached response body.
+type CacheEntry struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	Expires      time.Time
+}
+
+// Cache stores responses by request path and query. It must be safe for
+// concurrent use. Entries must not be modified once stored.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, e *CacheEntry)
+	Delete(key string)
+	// DeletePrefix deletes the entries whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// CachePolicy tells which GET responses are cached and for how long.
+//
+// A key of TTL matches the path of a request relative to the base URL, e.g.
+// "models", or the paths starting with it if it ends in "/", e.g.
+// "templates/params/". The longest matching key applies. Responses of other
+// paths are not cached.
+//
+// Writes through the client delete the cached responses of the resource
+// written, e.g. every "terminals" response after a terminal update. A
+// Cache shared by clients with different API keys mixes their data.
+type CachePolicy struct {
+	Cache Cache // an LRUCache of 256 entries if nil
+	TTL   map[string]time.Duration
+}
+
+// DefaultCachePolicy caches the models for an hour, and the sub-companies
+// and templates lists for 5 minutes.
+func DefaultCachePolicy() *CachePolicy {
+	return &CachePolicy{
+		TTL: map[string]time.Duration{
+			"models":          time.Hour,
+			"client/children": 5 * time.Minute,
+			"templates":       5 * time.Minute,
+		},
+	}
+}
+
+func (p *CachePolicy) ttl(path string) time.Duration {
+	best, ttl := -1, time.Duration(0)
+	for k, d := range p.TTL {
+		if (k == path || strings.HasSuffix(k, "/") && strings.HasPrefix(path, k)) && len(k) > best {
+			best, ttl = len(k), d
+		}
+	}
+	return ttl
+}
+
+// SetCache enables caching of GET responses according to p. A nil policy
+// disables caching.
+func (c *Client) SetCache(p *CachePolicy) {
+	if p != nil && p.Cache == nil {
+		pp := *p
+		pp.Cache = NewLRUCache(0)
+		p = &pp
+	}
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.cache = p
+}
+
+func (c *Client) cachePolicy() *CachePolicy {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.cache
+}
+
+// InvalidateCache deletes the cached responses of the paths starting with
+// prefix, all of them if prefix is empty.
+func (c *Client) InvalidateCache(prefix string) {
+	if p := c.cachePolicy(); p != nil {
+		p.Cache.DeletePrefix(prefix)
+	}
+}
+
+// cacheFor returns the cache, key and time to live of the response of a
+// request, or a nil cache if it is not cached.
+func (c *Client) cacheFor(method string, path url.URL) (Cache, string, time.Duration) {
+	p := c.cachePolicy()
+	if p == nil || method != "GET" {
+		return nil, "", 0
+	}
+	rel := strings.TrimPrefix(path.Path, "/")
+	ttl := p.ttl(rel)
+	if ttl <= 0 {
+		return nil, "", 0
+	}
+	key := rel
+	if path.RawQuery != "" {
+		key += "?" + path.RawQuery
+	}
+	return p.Cache, key, ttl
+}
+
+// invalidate deletes the cached responses of the resource written by a
+// request to path.
+func (c *Client) invalidate(method string, path url.URL) {
+	if method == "GET" {
+		return
+	}
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path.Path, "/"), "/")
+	c.InvalidateCache(resource)
+}
+
+// LRUCache is an in-memory Cache evicting the least recently used entries.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewLRUCache returns a cache of up to size entries, 256 if size is not
+// positive.
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = 256
+	}
+	return &LRUCache{size: size, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func (l *LRUCache) Get(key string) (*CacheEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (l *LRUCache) Set(key string, e *CacheEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.items[key]; ok {
+		el.Value.(*lruItem).entry = e
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruItem{key: key, entry: e})
+	for l.ll.Len() > l.size {
+		el := l.ll.Back()
+		l.ll.Remove(el)
+		delete(l.items, el.Value.(*lruItem).key)
+	}
+}
+
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.items[key]; ok {
+		l.ll.Remove(el)
+		delete(l.items, key)
+	}
+}
+
+func (l *LRUCache) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, el := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.ll.Remove(el)
+			delete(l.items, key)
+		}
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
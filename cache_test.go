@@ -0,0 +1,218 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const modelsBody = `{"success":true,"data":{"count":1,"rows":[{"name":"TEST1","id":"test1"}]}}`
+
+func TestCacheHit(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls int32
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, modelsBody)
+	})
+	c.SetCache(DefaultCachePolicy())
+
+	for i := 0; i < 3; i++ {
+		ml, err := c.ModelsService.List(context.Background())
+		if err != nil {
+			t.Fatalf("List returned error: %v", err)
+		}
+		if ml.Count != 1 || ml.Rows[0].ID != "test1" {
+			t.Errorf("List = %+v", ml)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+
+	c.InvalidateCache("models")
+	if _, err := c.ModelsService.List(context.Background()); err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times after invalidation, want 2", calls)
+	}
+}
+
+func TestCacheQueryKeys(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls int32
+	mux.HandleFunc("/templates", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"success":true,"data":{"count":0,"rows":[]}}`)
+	})
+	c.SetCache(DefaultCachePolicy())
+
+	for _, page := range []int{1, 2, 1, 2} {
+		if _, err := c.TemplatesService.List(context.Background(), &TemplatesOpt{Page: page}); err != nil {
+			t.Fatalf("List returned error: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2", calls)
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var full, notModified int32
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, modelsBody)
+	})
+	cache := NewLRUCache(10)
+	c.SetCache(&CachePolicy{Cache: cache, TTL: map[string]time.Duration{"models": time.Hour}})
+
+	if _, err := c.ModelsService.List(context.Background()); err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	e, ok := cache.Get("models")
+	if !ok || e.ETag != `"v1"` {
+		t.Fatalf("cached entry = %+v, %v", e, ok)
+	}
+	expired := *e
+	expired.Expires = time.Now().Add(-time.Second)
+	cache.Set("models", &expired)
+
+	ml, err := c.ModelsService.List(context.Background())
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if ml.Count != 1 {
+		t.Errorf("List count = %d, want 1", ml.Count)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("full = %d, not modified = %d, want 1 and 1", full, notModified)
+	}
+	if e, _ := cache.Get("models"); !e.Expires.After(time.Now()) {
+		t.Errorf("revalidated entry expires %v", e.Expires)
+	}
+}
+
+func TestCacheInvalidatedByWrites(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var lists int32
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&lists, 1)
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"count":0,"rows":[]}}`)
+	})
+	mux.HandleFunc("/terminals/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPut)
+		fmt.Fprint(w, `{"success":true}`)
+	})
+	c.SetCache(&CachePolicy{TTL: map[string]time.Duration{"terminals": time.Hour}})
+
+	list := func() {
+		if _, err := c.TerminalsService.List(context.Background(), nil); err != nil {
+			t.Fatalf("List returned error: %v", err)
+		}
+	}
+	list()
+	list()
+	if err := c.TerminalsService.Update(context.Background(), 1, &NewTerminal{Name: "n"}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	list()
+	if lists != 2 {
+		t.Errorf("terminals listed %d times, want 2", lists)
+	}
+}
+
+func TestCacheErrorsNotCached(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls int32
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"success":false,"message":"boom"}`)
+			return
+		}
+		fmt.Fprint(w, modelsBody)
+	})
+	c.SetCache(DefaultCachePolicy())
+
+	if _, err := c.ModelsService.List(context.Background()); err == nil {
+		t.Fatal("List returned no error")
+	}
+	if _, err := c.ModelsService.List(context.Background()); err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2", calls)
+	}
+}
+
+func TestCachePolicyTTL(t *testing.T) {
+	p := &CachePolicy{TTL: map[string]time.Duration{
+		"templates":         time.Minute,
+		"templates/params/": time.Second,
+	}}
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"templates", time.Minute},
+		{"templates/params/1", time.Second},
+		{"templates/params", 0},
+		{"templates2", 0},
+		{"models", 0},
+	}
+	for _, tt := range tests {
+		if got := p.ttl(tt.path); got != tt.want {
+			t.Errorf("ttl(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	l := NewLRUCache(2)
+	l.Set("a", &CacheEntry{Body: []byte("a")})
+	l.Set("b", &CacheEntry{Body: []byte("b")})
+	l.Get("a")
+	l.Set("c", &CacheEntry{Body: []byte("c")})
+
+	if _, ok := l.Get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.Get(key); !ok {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+
+	l.Set("ab", &CacheEntry{})
+	l.DeletePrefix("a")
+	if l.Len() != 1 {
+		t.Errorf("Len after DeletePrefix = %d, want 1", l.Len())
+	}
+	l.Delete("c")
+	if l.Len() != 0 {
+		t.Errorf("Len after Delete = %d, want 0", l.Len())
+	}
+}
@@ -0,0 +1,504 @@
+package amp360
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoInteraction is returned by a ReplayRoundTripper for the requests
+// matching none of the remaining interactions of its cassette.
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+const redacted = "REDACTED"
+
+// Interaction is a request and its response, recorded by a
+// RecordingRoundTripper.
+type Interaction struct {
+	Time     time.Time        `json:"time"`
+	Duration time.Duration    `json:"duration"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+	// Error is the transport error of the request, if it got no response.
+	Error string `json:"error,omitempty"`
+}
+
+// RecordedRequest is a recorded request. Binary bodies, such as multipart
+// uploads of files, are encoded in base64.
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+	// Truncated is set when the body was longer than the MaxBodySize of the
+	// recorder and only its start was recorded.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RecordedResponse is a recorded response. A truncated body is replayed as
+// recorded.
+type RecordedResponse struct {
+	Status       string      `json:"status,omitempty"`
+	StatusCode   int         `json:"statusCode,omitempty"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+	Truncated    bool        `json:"truncated,omitempty"`
+}
+
+func encodeBody(b []byte) (string, string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return nil, fmt.Errorf("unknown body encoding %q", encoding)
+}
+
+// BodyBytes returns the decoded body of the request.
+func (r *RecordedRequest) BodyBytes() ([]byte, error) {
+	return decodeBody(r.Body, r.BodyEncoding)
+}
+
+// BodyBytes returns the decoded body of the response.
+func (r *RecordedResponse) BodyBytes() ([]byte, error) {
+	return decodeBody(r.Body, r.BodyEncoding)
+}
+
+// Cassette is a sequence of interactions, stored as JSON lines.
+type Cassette struct {
+	Interactions []Interaction
+}
+
+// LoadCassette reads the cassette at path.
+func LoadCassette(path string) (*Cassette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCassette(f)
+}
+
+// ReadCassette reads a cassette from r.
+func ReadCassette(r io.Reader) (*Cassette, error) {
+	c := &Cassette{}
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var in Interaction
+		if err := dec.Decode(&in); err == io.EOF {
+			return c, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("cassette: interaction %d: %w", len(c.Interactions)+1, err)
+		}
+		c.Interactions = append(c.Interactions, in)
+	}
+}
+
+// Scrubber removes secrets from the recorded interactions. Its fields must
+// not change once it is in use.
+type Scrubber struct {
+	// Headers lists the headers whose values are replaced.
+	Headers []string
+	// Fields lists the JSON fields, form fields and query parameters whose
+	// values are replaced.
+	Fields []string
+
+	once      sync.Once
+	jsonField *regexp.Regexp // nil without Fields
+	jsonTail  *regexp.Regexp // a field whose value is cut off
+	formField *regexp.Regexp
+}
+
+// DefaultScrubber removes the API key and the authentication codes of the
+// terminals, cloudAuthCode in responses and customAuthCode in requests.
+func DefaultScrubber() *Scrubber {
+	return &Scrubber{
+		Headers: []string{"Authorization"},
+		Fields:  []string{"cloudAuthCode", "customAuthCode"},
+	}
+}
+
+func (s *Scrubber) header(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range s.Headers {
+		if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+			h.Set(name, redacted)
+		}
+	}
+	return h
+}
+
+func (s *Scrubber) url(u *url.URL) string {
+	q := u.Query()
+	changed := false
+	for _, name := range s.Fields {
+		if _, ok := q[name]; ok {
+			q.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	uu := *u
+	uu.RawQuery = q.Encode()
+	return uu.String()
+}
+
+// compile builds the patterns matching the values of Fields in bodies.
+func (s *Scrubber) compile() {
+	if len(s.Fields) == 0 {
+		return
+	}
+	names := make([]string, len(s.Fields))
+	for i, name := range s.Fields {
+		names[i] = regexp.QuoteMeta(name)
+	}
+	q := "(?:" + strings.Join(names, "|") + ")"
+	key := `("` + q + `"\s*:\s*)`
+	s.jsonField = regexp.MustCompile(key + `(?:"(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*|true|false|null)`)
+	s.jsonTail = regexp.MustCompile(key + `(?:"(?:[^"\\]|\\.)*\\?|[-0-9.eE+a-z]*)$`)
+	s.formField = regexp.MustCompile(`(name="` + q + `"\r\n(?:[^\r\n]+\r\n)*\r\n)[^\r]*`)
+}
+
+func (s *Scrubber) body(b []byte) []byte {
+	s.once.Do(s.compile)
+	if s.jsonField == nil {
+		return b
+	}
+	b = s.jsonField.ReplaceAll(b, []byte(`${1}"`+redacted+`"`))
+	return s.formField.ReplaceAll(b, []byte("${1}"+redacted))
+}
+
+// head scrubs b, the start of a body read by peekBody, and cuts it to max
+// bytes, reporting whether it did. A value cut off at the end of the kept
+// bytes is scrubbed as well.
+func (s *Scrubber) head(b []byte, max int64) ([]byte, bool) {
+	if int64(len(b)) <= max {
+		return s.body(b), false
+	}
+	b = s.body(b)
+	if int64(len(b)) > max {
+		b = b[:max]
+	}
+	if s.jsonTail != nil {
+		b = s.jsonTail.ReplaceAll(b, []byte(`${1}"`+redacted+`"`))
+	}
+	return b, true
+}
+
+// defaultScrubber is the Scrubber of the recorders without one.
+var defaultScrubber = DefaultScrubber()
+
+// RecordingRoundTripper records the requests passing through Wrapped, along
+// with their responses, to a cassette. Every interaction is appended to the
+// cassette as soon as its response headers and the recorded start of its
+// body are received. Up to MaxBodySize bytes of every body are held in
+// memory; the rest is streamed through without being recorded.
+type RecordingRoundTripper struct {
+	Wrapped http.RoundTripper
+	// Scrubber removes the secrets of the interactions, DefaultScrubber if
+	// nil.
+	Scrubber *Scrubber
+	// MaxBodySize is the number of bytes of a body recorded,
+	// DefaultMaxBodySize if zero.
+	MaxBodySize int64
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// DefaultMaxBodySize is the number of bytes of a body recorded by default.
+const DefaultMaxBodySize = 1 << 20
+
+// NewRecordingRoundTripper returns a round tripper writing the interactions
+// of wrapped, http.DefaultTransport if nil, to w.
+func NewRecordingRoundTripper(wrapped http.RoundTripper, w io.Writer) *RecordingRoundTripper {
+	if wrapped == nil {
+		wrapped = http.DefaultTransport
+	}
+	return &RecordingRoundTripper{Wrapped: wrapped, w: w}
+}
+
+// RecordToFile returns a round tripper writing the interactions of wrapped
+// to a new cassette at path. It must be closed once done.
+func RecordToFile(wrapped http.RoundTripper, path string) (*RecordingRoundTripper, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecordingRoundTripper(wrapped, f)
+	r.closer = f
+	return r, nil
+}
+
+// Close closes the cassette file opened by RecordToFile.
+func (r *RecordingRoundTripper) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closer == nil {
+		return nil
+	}
+	err := r.closer.Close()
+	r.closer = nil
+	return err
+}
+
+func (r *RecordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	scrub := r.Scrubber
+	if scrub == nil {
+		scrub = defaultScrubber
+	}
+	max := r.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+
+	in := Interaction{
+		Time: time.Now().UTC(),
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    scrub.url(req.URL),
+			Header: scrub.header(req.Header),
+		},
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		head, body, err := peekBody(req.Body, max)
+		if err != nil {
+			req.Body.Close()
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+		head, in.Request.Truncated = scrub.head(head, max)
+		in.Request.Body, in.Request.BodyEncoding = encodeBody(head)
+	}
+
+	start := time.Now()
+	res, err := r.Wrapped.RoundTrip(req)
+	in.Duration = time.Since(start)
+	if err != nil {
+		in.Error = err.Error()
+		return nil, r.write(&in, err)
+	}
+
+	head, body, err := peekBody(res.Body, max)
+	if err != nil {
+		res.Body.Close()
+		in.Error = err.Error()
+		return nil, r.write(&in, err)
+	}
+	res.Body = body
+	in.Response = RecordedResponse{
+		Status:     res.Status,
+		StatusCode: res.StatusCode,
+		Header:     scrub.header(res.Header),
+	}
+	head, in.Response.Truncated = scrub.head(head, max)
+	in.Response.Body, in.Response.BodyEncoding = encodeBody(head)
+	if err := r.write(&in, nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// peekBody reads up to max+1 bytes of body and returns them with a body
+// reading them again followed by the rest of body.
+func peekBody(body io.ReadCloser, max int64) ([]byte, io.ReadCloser, error) {
+	head, err := io.ReadAll(io.LimitReader(body, max+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(head)) <= max {
+		body.Close()
+		return head, io.NopCloser(bytes.NewReader(head)), nil
+	}
+	return head, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}, nil
+}
+
+// write appends in to the cassette and returns err, or the error of the
+// write if err is nil.
+func (r *RecordingRoundTripper) write(in *Interaction, err error) error {
+	b, merr := json.Marshal(in)
+	if merr != nil {
+		return errors.Join(err, merr)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, werr := r.w.Write(append(b, '\n')); werr != nil && err == nil {
+		return fmt.Errorf("cassette: %w", werr)
+	}
+	return err
+}
+
+// MatchFunc tells whether the recorded request rec matches req, whose body
+// is body.
+type MatchFunc func(req *http.Request, body []byte, rec *RecordedRequest) bool
+
+// MatchMethod matches the requests of the same method.
+func MatchMethod(req *http.Request, _ []byte, rec *RecordedRequest) bool {
+	return req.Method == rec.Method
+}
+
+// MatchPath matches the requests of the same URL path, whatever the host.
+func MatchPath(req *http.Request, _ []byte, rec *RecordedRequest) bool {
+	u, err := url.Parse(rec.URL)
+	return err == nil && u.Path == req.URL.Path
+}
+
+// MatchQuery matches the requests of the same query parameters, in any
+// order.
+func MatchQuery(req *http.Request, _ []byte, rec *RecordedRequest) bool {
+	u, err := url.Parse(rec.URL)
+	return err == nil && u.Query().Encode() == req.URL.Query().Encode()
+}
+
+// MatchBody matches the requests of the same body. Multipart bodies only
+// match if they share the boundary.
+func MatchBody(req *http.Request, body []byte, rec *RecordedRequest) bool {
+	b, err := rec.BodyBytes()
+	return err == nil && bytes.Equal(b, body)
+}
+
+// MatchAll matches the requests matched by all of fns.
+func MatchAll(fns ...MatchFunc) MatchFunc {
+	return func(req *http.Request, body []byte, rec *RecordedRequest) bool {
+		for _, fn := range fns {
+			if !fn(req, body, rec) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// DefaultMatch matches the requests of the same method, path and query.
+var DefaultMatch = MatchAll(MatchMethod, MatchPath, MatchQuery)
+
+// ReplayRoundTripper answers requests with the responses of a cassette,
+// without sending them. A request gets the response of the first
+// interaction it matches that was not replayed yet.
+type ReplayRoundTripper struct {
+	Cassette *Cassette
+	// Match tells which interactions match a request, DefaultMatch if nil.
+	Match MatchFunc
+	// Repeat lets the interactions be replayed any number of times.
+	Repeat bool
+
+	mu   sync.Mutex
+	used map[int]bool
+}
+
+// NewReplayRoundTripper returns a round tripper replaying the cassette at
+// path.
+func NewReplayRoundTripper(path string) (*ReplayRoundTripper, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayRoundTripper{Cassette: c}, nil
+}
+
+func (r *ReplayRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	match := r.Match
+	if match == nil {
+		match = DefaultMatch
+	}
+
+	r.mu.Lock()
+	in := r.next(req, body, match)
+	r.mu.Unlock()
+	if in == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+	}
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+
+	resBody, err := in.Response.BodyBytes()
+	if err != nil {
+		return nil, err
+	}
+	header := in.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	status := in.Response.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode))
+	}
+	return &http.Response{
+		Status:        status,
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
+
+// next returns the interaction answering req, nil if none.
+func (r *ReplayRoundTripper) next(req *http.Request, body []byte, match MatchFunc) *Interaction {
+	if r.Cassette == nil {
+		return nil
+	}
+	if r.used == nil {
+		r.used = map[int]bool{}
+	}
+	for i := range r.Cassette.Interactions {
+		in := &r.Cassette.Interactions[i]
+		if (r.Repeat || !r.used[i]) && match(req, body, &in.Request) {
+			r.used[i] = true
+			return in
+		}
+	}
+	return nil
+}
+
+// Remaining returns the number of interactions not replayed yet.
+func (r *ReplayRoundTripper) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Cassette == nil {
+		return 0
+	}
+	return len(r.Cassette.Interactions) - len(r.used)
+}
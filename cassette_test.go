@@ -0,0 +1,232 @@
+package amp360
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		fmt.Fprint(w, `{"success":true,"data":{"id":7,"serialNumber":"S1","cloudAuthCode":"secret-code"}}`)
+	})
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"count":1,"rows":[{"name":"TEST1","id":"test1"}]}}`)
+	})
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	rec, err := RecordToFile(nil, path)
+	if err != nil {
+		t.Fatalf("RecordToFile returned error: %v", err)
+	}
+	c.SetTransport(rec)
+	c.SetAPIKey("secret-key")
+
+	created, err := c.TerminalsService.Add(context.Background(), &NewTerminal{SerialNumber: "S1", CloudAuthCode: "secret-code"})
+	if err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if created.ID != 7 || created.CloudAuthCode != "secret-code" {
+		t.Errorf("recorded response altered: %+v", created)
+	}
+	if _, err := c.ModelsService.List(context.Background()); err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	cas, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	if len(cas.Interactions) != 2 {
+		t.Fatalf("recorded %d interactions, want 2", len(cas.Interactions))
+	}
+	for _, in := range cas.Interactions {
+		if got := in.Request.Header.Get("Authorization"); got != "REDACTED" {
+			t.Errorf("Authorization = %q, want REDACTED", got)
+		}
+		if strings.Contains(in.Request.Body+in.Response.Body, "secret") {
+			t.Errorf("secret not scrubbed from %s %s", in.Request.Method, in.Request.URL)
+		}
+	}
+	if in := cas.Interactions[0]; !strings.Contains(in.Response.Body, `"cloudAuthCode":"REDACTED"`) {
+		t.Errorf("response body = %s", in.Response.Body)
+	}
+
+	// replay against an unreachable host
+	r := NewClient("http://replay.invalid/api-test/", &http.Client{Transport: &ReplayRoundTripper{Cassette: cas}})
+	ml, err := r.ModelsService.List(context.Background())
+	if err != nil {
+		t.Fatalf("replayed List returned error: %v", err)
+	}
+	if ml.Count != 1 {
+		t.Errorf("replayed count = %d, want 1", ml.Count)
+	}
+	created, err = r.TerminalsService.Add(context.Background(), &NewTerminal{SerialNumber: "S1"})
+	if err != nil {
+		t.Fatalf("replayed Add returned error: %v", err)
+	}
+	if created.ID != 7 {
+		t.Errorf("replayed ID = %d, want 7", created.ID)
+	}
+	_, err = r.ModelsService.List(context.Background())
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("second replay returned %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecordMultipart(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals/params/bulk/1", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm returned error: %v", err)
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"updated":[],"failed":[]}}`)
+	})
+
+	var buf bytes.Buffer
+	c.SetTransport(NewRecordingRoundTripper(nil, &buf))
+	up := &ParamsUpload{
+		Params: map[string]string{"cloudAuthCode": "secret-code", "tid": "1234"},
+		Files:  []ParamFile{{Param: "logo", Name: "logo.bin", Reader: bytes.NewReader([]byte{0xff, 0xfe, 0x00})}},
+	}
+	if _, err := c.TerminalsService.UploadParams(context.Background(), 1, up); err != nil {
+		t.Fatalf("UploadParams returned error: %v", err)
+	}
+
+	cas, err := ReadCassette(&buf)
+	if err != nil {
+		t.Fatalf("ReadCassette returned error: %v", err)
+	}
+	if len(cas.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(cas.Interactions))
+	}
+	req := cas.Interactions[0].Request
+	if req.BodyEncoding != "base64" {
+		t.Errorf("BodyEncoding = %q, want base64", req.BodyEncoding)
+	}
+	body, err := req.BodyBytes()
+	if err != nil {
+		t.Fatalf("BodyBytes returned error: %v", err)
+	}
+	if bytes.Contains(body, []byte("secret-code")) {
+		t.Error("cloudAuthCode form field not scrubbed")
+	}
+	if !bytes.Contains(body, []byte("1234")) || !bytes.Contains(body, []byte{0xff, 0xfe, 0x00}) {
+		t.Error("multipart body not recorded")
+	}
+}
+
+func TestReplayMatching(t *testing.T) {
+	cas := &Cassette{Interactions: []Interaction{
+		{
+			Request:  RecordedRequest{Method: "GET", URL: "https://a.example/v1/terminals?page=1&size=2"},
+			Response: RecordedResponse{StatusCode: 200, Body: "first"},
+		},
+		{
+			Request:  RecordedRequest{Method: "GET", URL: "https://a.example/v1/terminals?page=2&size=2"},
+			Response: RecordedResponse{StatusCode: 200, Body: "second"},
+		},
+		{
+			Request: RecordedRequest{Method: "GET", URL: "https://a.example/v1/models"},
+			Error:   "connection reset",
+		},
+	}}
+
+	get := func(rt http.RoundTripper, url string) (string, error) {
+		req, _ := http.NewRequest("GET", url, nil)
+		res, err := rt.RoundTrip(req)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		var b bytes.Buffer
+		b.ReadFrom(res.Body)
+		return b.String(), nil
+	}
+
+	rt := &ReplayRoundTripper{Cassette: cas}
+	if got, _ := get(rt, "http://b.example/v1/terminals?size=2&page=2"); got != "second" {
+		t.Errorf("query match = %q, want second", got)
+	}
+	if _, err := get(rt, "http://b.example/v1/models"); err == nil || err.Error() != "connection reset" {
+		t.Errorf("recorded error = %v", err)
+	}
+	if rt.Remaining() != 1 {
+		t.Errorf("Remaining = %d, want 1", rt.Remaining())
+	}
+
+	rt = &ReplayRoundTripper{Cassette: cas, Match: MatchAll(MatchMethod, MatchPath), Repeat: true}
+	for i := 0; i < 2; i++ {
+		if got, _ := get(rt, "http://b.example/v1/terminals?page=9"); got != "first" {
+			t.Errorf("path match = %q, want first", got)
+		}
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	s := &Scrubber{Fields: []string{"token"}}
+	body := s.body([]byte(`{"token": "a\"b", "other":"x"}`))
+	if string(body) != `{"token": "REDACTED", "other":"x"}` {
+		t.Errorf("body = %s", body)
+	}
+	body = s.body([]byte(`{"token":123456,"flag":true,"other":{"token":null}}`))
+	if string(body) != `{"token":"REDACTED","flag":true,"other":{"token":"REDACTED"}}` {
+		t.Errorf("body = %s", body)
+	}
+	// peekBody reads max+1 bytes, which may end inside a value
+	for _, b := range []string{`{"token":"secret-value`, `{"token":"secret-value"}`, `{"token":12345678`} {
+		head, truncated := s.head([]byte(b), int64(len(b)-3))
+		if !truncated || strings.ContainsAny(string(head), "12sc") {
+			t.Errorf("head of %s = %s, truncated %v", b, head, truncated)
+		}
+	}
+	u, _ := http.NewRequest("GET", "https://a.example/p?token=abc&x=1", nil)
+	if got := s.url(u.URL); got != "https://a.example/p?token=REDACTED&x=1" {
+		t.Errorf("url = %s", got)
+	}
+}
+
+func TestRecordMaxBodySize(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	content := strings.Repeat("x", 100)
+	mux.HandleFunc("/files/big.bin", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	})
+
+	var buf bytes.Buffer
+	rec := NewRecordingRoundTripper(nil, &buf)
+	rec.MaxBodySize = 10
+	c.SetTransport(rec)
+	var got bytes.Buffer
+	if _, err := c.Download(context.Background(), "files/big.bin", &got); err != nil {
+		t.Fatalf("Download returned error: %v", err)
+	}
+	if got.String() != content {
+		t.Errorf("downloaded %d bytes, want %d", got.Len(), len(content))
+	}
+
+	cas, err := ReadCassette(&buf)
+	if err != nil {
+		t.Fatalf("ReadCassette returned error: %v", err)
+	}
+	res := cas.Interactions[0].Response
+	if !res.Truncated || res.Body != content[:10] {
+		t.Errorf("recorded response = %+v", res)
+	}
+}
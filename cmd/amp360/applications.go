@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+// usageRow is a template running a version of an application.
+type usageRow struct {
+	Version    string `json:"version"`
+	TemplateID int    `json:"templateId"`
+	Template   string `json:"template"`
+}
+
+func (a *app) applicationsUsage(args []string) error {
+	fs := a.flagSet("applications usage")
+	version := fs.String("version", "", "only the templates with this version")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected an application name", errUsage)
+	}
+	usage, err := a.client.ApplicationsService.Usage(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	if *version != "" {
+		usage = map[string][]amp360.Template{*version: usage[*version]}
+	}
+
+	versions := make([]string, 0, len(usage))
+	for v := range usage {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	rows := []usageRow{}
+	table := [][]string{}
+	for _, v := range versions {
+		for _, t := range usage[v] {
+			rows = append(rows, usageRow{Version: v, TemplateID: t.ID, Template: t.Name})
+			table = append(table, []string{v, strconv.Itoa(t.ID), t.Name})
+		}
+	}
+	return a.out.print(rows, []string{"VERSION", "TEMPLATE ID", "TEMPLATE"}, table)
+}
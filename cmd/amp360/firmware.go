@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+func (a *app) firmwareCompliance(args []string) error {
+	fs := a.flagSet("firmware compliance")
+	opt := &amp360.ComplianceOpt{}
+	outdated := fs.Bool("outdated", false, "only list the terminals not on the latest firmware")
+	fs.IntVar(&opt.Workers, "workers", 4, "terminals checked concurrently")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	r, err := a.client.FirmwareService.Compliance(context.Background(), opt)
+	if err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(a.stderr, "amp360: terminal %d (%s): %s\n", e.TerminalID, e.SerialNumber, e.Error)
+	}
+	if *outdated {
+		r.Groups = r.OutdatedGroups()
+	}
+	var table [][]string
+	for _, g := range r.Groups {
+		latest := "no"
+		if g.Latest {
+			latest = "yes"
+		}
+		for _, t := range g.Terminals {
+			queued := ""
+			if t.Queued {
+				queued = "yes"
+			}
+			table = append(table, []string{g.Model, g.Firmware, g.Version, latest, strconv.Itoa(t.ID), t.SerialNumber, queued})
+		}
+	}
+	return a.out.print(r, []string{"MODEL", "FIRMWARE", "VERSION", "LATEST", "TERMINAL", "SERIAL", "QUEUED"}, table)
+}
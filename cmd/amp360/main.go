@@ -0,0 +1,356 @@
+// Command amp360 administers terminals, templates and companies through the
+// AMP360 API.
+//
+// Usage:
+//
+//	amp360 [flags] <command> <subcommand> [arguments]
+//
+// The API key is read from the -api-key flag, the AMP360_API_KEY environment
+// variable or the config file, in that order. A key file, set with
+// -api-key-file, AMP360_API_KEY_FILE or api_key_file in the config file, is
+// read again whenever it changes.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrei-cloud/amp360"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes of the command.
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitAuth       = 3
+	exitNotFound   = 4
+	exitConflict   = 5
+	exitBadRequest = 6
+	exitAPI        = 7
+)
+
+var errUsage = errors.New("usage error")
+
+const usage = `Usage: amp360 [flags] <command> <subcommand> [arguments]
+
+Commands:
+  terminals list [-serial S] [-tid T] [-mid M] [-size N] [-page N] [-all] [-max N] [-all-tenants]
+  terminals get <id> | -serial S
+  terminals create -serial S -model M [-name N] [-client C] [-template T] [key=value...]
+  terminals update <id> [-name N] [-client C]
+  terminals delete <id>
+  terminals onboard -manifest FILE [-workers N] [-on-conflict skip|update|fail] [-checkpoint FILE] [-report FILE]
+  terminals watch [-store FILE] [-interval D] [-details] [-once]
+  terminals params get <id> [-category C]
+  terminals params set <id> [-file key=path...] key=value...
+  templates list
+  templates params <id> [-category C]
+  templates export <id> -f FILE
+  templates import <id> -f FILE [-dry-run]
+  templates drift <id> [-terminal ID] [-serial S] [-editable] [-hidden] [-workers N]
+  applications usage <name> [-version V]
+  firmware compliance [-outdated] [-workers N]
+  reconcile plan -f FILE [-prune]
+  reconcile apply -f FILE [-prune] [-workers N]
+  companies list
+  companies tree [-depth N] [-workers N]
+  models list
+
+Flags:
+`
+
+// config is the content of the config file.
+type config struct {
+	APIKey     string          `yaml:"api_key"`
+	APIKeyFile string          `yaml:"api_key_file"`
+	Tenants    []amp360.Tenant `yaml:"tenants"`
+	Env        string          `yaml:"env"`
+	Output     string          `yaml:"output"`
+}
+
+// app holds what subcommands need.
+type app struct {
+	client *amp360.Client
+	pool   *amp360.ClientPool // clients of the tenants of the config file
+	out    *printer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	fs := flag.NewFlagSet("amp360", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	apiKey := fs.String("api-key", "", "API key (default $AMP360_API_KEY)")
+	apiKeyFile := fs.String("api-key-file", "", "file holding the API key (default $AMP360_API_KEY_FILE)")
+	env := fs.String("env", "", `API environment: "prod", "dev" or a base URL (default $AMP360_ENV)`)
+	configPath := fs.String("config", "", "config file (default $AMP360_CONFIG or <user config dir>/amp360/config.yaml)")
+	tenant := fs.String("tenant", "", "tenant of the config file to act as (default $AMP360_TENANT)")
+	output := fs.String("o", "", "output format: table, csv, json or yaml")
+	record := fs.String("record", "", "record the API traffic, without secrets, to a cassette file")
+	replay := fs.String("replay", "", "answer the API requests from a cassette file instead of the API")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(*configPath, getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "amp360: %v\n", err)
+		return exitError
+	}
+	key := firstOf(*apiKey, getenv("AMP360_API_KEY"), cfg.APIKey)
+	keyFile := firstOf(*apiKeyFile, getenv("AMP360_API_KEY_FILE"), cfg.APIKeyFile)
+	base := firstOf(*env, getenv("AMP360_ENV"), cfg.Env)
+	if base == "prod" {
+		base = ""
+	}
+	format := firstOf(*output, cfg.Output, "table")
+
+	p, err := newPrinter(stdout, format)
+	if err != nil {
+		fmt.Fprintf(stderr, "amp360: %v\n", err)
+		return exitUsage
+	}
+
+	httpClient := &http.Client{}
+	if *replay != "" {
+		rt, err := amp360.NewReplayRoundTripper(*replay)
+		if err != nil {
+			fmt.Fprintf(stderr, "amp360: %v\n", err)
+			return exitError
+		}
+		httpClient.Transport = rt
+	}
+	if *record != "" {
+		rt, err := amp360.RecordToFile(httpClient.Transport, *record)
+		if err != nil {
+			fmt.Fprintf(stderr, "amp360: %v\n", err)
+			return exitError
+		}
+		defer rt.Close()
+		httpClient.Transport = rt
+	}
+
+	a := &app{
+		client: amp360.NewClient(base, httpClient),
+		out:    p,
+		stderr: stderr,
+	}
+	if key == "" && keyFile != "" {
+		a.client.SetCredentials(amp360.NewFileCredentials(keyFile))
+	} else {
+		a.client.SetAPIKey(key)
+	}
+	if len(cfg.Tenants) > 0 {
+		a.pool = amp360.NewClientPool(base, httpClient)
+		if err := a.pool.Load(cfg.Tenants); err != nil {
+			fmt.Fprintf(stderr, "amp360: %v\n", err)
+			return exitError
+		}
+	}
+	if name := firstOf(*tenant, getenv("AMP360_TENANT")); name != "" {
+		var c *amp360.Client
+		if a.pool != nil {
+			c, _ = a.pool.Client(name)
+		}
+		if c == nil {
+			fmt.Fprintf(stderr, "amp360: unknown tenant %q\n", name)
+			return exitUsage
+		}
+		a.client = c
+	}
+
+	err = a.dispatch(fs.Args())
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			if err != errUsage {
+				fmt.Fprintf(stderr, "amp360: %v\n", err)
+			}
+			fs.Usage()
+		} else {
+			fmt.Fprintf(stderr, "amp360: %v\n", err)
+		}
+	}
+	return exitCode(err)
+}
+
+func (a *app) dispatch(args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	cmd, args := args[0]+" "+args[1], args[2:]
+	if cmd == "terminals params" && len(args) > 0 {
+		cmd, args = cmd+" "+args[0], args[1:]
+	}
+
+	switch cmd {
+	case "terminals list":
+		return a.terminalsList(args)
+	case "terminals get":
+		return a.terminalsGet(args)
+	case "terminals create":
+		return a.terminalsCreate(args)
+	case "terminals update":
+		return a.terminalsUpdate(args)
+	case "terminals delete":
+		return a.terminalsDelete(args)
+	case "terminals onboard":
+		return a.terminalsOnboard(args)
+	case "terminals watch":
+		return a.terminalsWatch(args)
+	case "terminals params get":
+		return a.terminalsParamsGet(args)
+	case "terminals params set":
+		return a.terminalsParamsSet(args)
+	case "templates list":
+		return a.templatesList(args)
+	case "templates params":
+		return a.templatesParams(args)
+	case "templates export":
+		return a.templatesExport(args)
+	case "templates import":
+		return a.templatesImport(args)
+	case "templates drift":
+		return a.templatesDrift(args)
+	case "applications usage":
+		return a.applicationsUsage(args)
+	case "firmware compliance":
+		return a.firmwareCompliance(args)
+	case "reconcile plan":
+		return a.reconcilePlan(args)
+	case "reconcile apply":
+		return a.reconcileApply(args)
+	case "companies list":
+		return a.companiesList(args)
+	case "companies tree":
+		return a.companiesTree(args)
+	case "models list":
+		return a.modelsList(args)
+	}
+	return errUsage
+}
+
+// exitCode maps err to the exit code of the command.
+func exitCode(err error) int {
+	var apiErr *amp360.APIError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, amp360.ErrIvalidToken), errors.Is(err, amp360.ErrNoPermission):
+		return exitAuth
+	case errors.Is(err, amp360.ErrEntityNotFound):
+		return exitNotFound
+	case errors.Is(err, amp360.ErrConflict):
+		return exitConflict
+	case errors.Is(err, amp360.ErrIncorrect):
+		return exitBadRequest
+	case errors.As(err, &apiErr):
+		return exitAPI
+	}
+	return exitError
+}
+
+func loadConfig(path string, getenv func(string) string) (*config, error) {
+	cfg := &config{}
+	explicit := true
+	if path == "" {
+		path = getenv("AMP360_CONFIG")
+	}
+	if path == "" {
+		explicit = false
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return cfg, nil
+		}
+		path = filepath.Join(dir, "amp360", "config.yaml")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// keyValues parses key=value arguments.
+func keyValues(args []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%w: expected key=value, got %q", errUsage, arg)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// flagSet returns a flag set for a subcommand.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseFlags parses args allowing flags after positional arguments, which
+// it returns.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+// multiFlag collects the values of a repeated flag.
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+)
+
+func newTestServer(t *testing.T) *amp360test.Server {
+	t.Helper()
+	srv := amp360test.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetAPIKey("key")
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{{
+			ID:     814,
+			Name:   "APITEST",
+			Params: []amp360.Param{{Tag: amp360test.DefaultMIDTag, Type: "STRING", Value: "000000000", CategoryName: "TERMINAL"}},
+		}},
+		Terminals: []amp360test.Terminal{{ID: 25, SerialNumber: "8000044499", Name: "T1", TemplateID: 814}},
+		Models:    []amp360.TerminalModel{{ID: "m1", Name: "AMP8000", HardwareID: "2AA", JointName: "AMP8000-2AA"}},
+	})
+	return srv
+}
+
+func runCmd(t *testing.T, srv *amp360test.Server, args ...string) (int, string, string) {
+	t.Helper()
+	env := map[string]string{
+		"AMP360_API_KEY": "key",
+		"AMP360_ENV":     srv.URL + amp360test.BasePath,
+		"AMP360_CONFIG":  "",
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(args, stdout, stderr, func(k string) string { return env[k] })
+	return code, stdout.String(), stderr.String()
+}
+
+func TestTerminalsCommands(t *testing.T) {
+	srv := newTestServer(t)
+
+	code, out, errOut := runCmd(t, srv, "terminals", "list")
+	if code != exitOK || !strings.Contains(out, "8000044499") || !strings.Contains(out, "APITEST") {
+		t.Errorf("terminals list exited %d:\n%s%s", code, out, errOut)
+	}
+
+	code, _, errOut = runCmd(t, srv, "terminals", "create", "-serial", "8000000001", "-model", "m1", "-template", "814", amp360test.DefaultMIDTag+"=400081203")
+	if code != exitOK {
+		t.Fatalf("terminals create exited %d: %s", code, errOut)
+	}
+	code, _, _ = runCmd(t, srv, "terminals", "create", "-serial", "8000000001", "-model", "m1")
+	if code != exitConflict {
+		t.Errorf("duplicate create exited %d, want %d", code, exitConflict)
+	}
+
+	code, out, _ = runCmd(t, srv, "-o", "json", "terminals", "params", "get", "26")
+	if code != exitOK || !strings.Contains(out, `"value": "400081203"`) {
+		t.Errorf("terminals params get exited %d:\n%s", code, out)
+	}
+
+	code, out, _ = runCmd(t, srv, "terminals", "params", "set", "26", amp360test.DefaultMIDTag+"=1")
+	if code != exitOK || !strings.Contains(out, "updated") {
+		t.Errorf("terminals params set exited %d:\n%s", code, out)
+	}
+
+	code, _, _ = runCmd(t, srv, "terminals", "update", "26", "-name", "renamed")
+	if code != exitOK {
+		t.Errorf("terminals update exited %d", code)
+	}
+	if term, _ := srv.Terminal(26); term.Name != "renamed" || term.SerialNumber == "" {
+		t.Errorf("updated terminal got %+v", term)
+	}
+
+	code, _, _ = runCmd(t, srv, "terminals", "delete", "26")
+	if code != exitOK {
+		t.Errorf("terminals delete exited %d", code)
+	}
+	code, _, _ = runCmd(t, srv, "terminals", "delete", "26")
+	if code != exitNotFound {
+		t.Errorf("second delete exited %d, want %d", code, exitNotFound)
+	}
+}
+
+func TestReferenceCommands(t *testing.T) {
+	srv := newTestServer(t)
+
+	code, out, _ := runCmd(t, srv, "-o", "yaml", "models", "list")
+	if code != exitOK || !strings.Contains(out, "jointName: AMP8000-2AA") {
+		t.Errorf("models list exited %d:\n%s", code, out)
+	}
+	code, out, _ = runCmd(t, srv, "templates", "params", "814")
+	if code != exitOK || !strings.Contains(out, amp360test.DefaultMIDTag) {
+		t.Errorf("templates params exited %d:\n%s", code, out)
+	}
+	code, out, _ = runCmd(t, srv, "templates", "list")
+	if code != exitOK || !strings.Contains(out, "APITEST") {
+		t.Errorf("templates list exited %d:\n%s", code, out)
+	}
+	code, _, _ = runCmd(t, srv, "companies", "list")
+	if code != exitOK {
+		t.Errorf("companies list exited %d", code)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	srv := newTestServer(t)
+
+	if code, _, _ := runCmd(t, srv, "terminals"); code != exitUsage {
+		t.Errorf("missing subcommand exited %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCmd(t, srv, "-o", "xml", "models", "list"); code != exitUsage {
+		t.Errorf("bad output exited %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := runCmd(t, srv, "-api-key", "wrong", "models", "list"); code != exitAuth {
+		t.Errorf("bad key exited %d, want %d", code, exitAuth)
+	}
+	srv.SetFaults(amp360test.Faults{FailNext: 1})
+	if code, _, _ := runCmd(t, srv, "models", "list"); code != exitAPI {
+		t.Errorf("server error exited %d, want %d", code, exitAPI)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("api_key: secret\nenv: dev\noutput: json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if cfg.APIKey != "secret" || cfg.Env != "dev" || cfg.Output != "json" {
+		t.Errorf("config got %+v", cfg)
+	}
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"), func(string) string { return "" }); err == nil {
+		t.Error("Error is nil for a missing explicit config")
+	}
+}
+
+func TestOnboardCommand(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.csv")
+	report := filepath.Join(dir, "report.csv")
+	content := "serial,model,template," + amp360test.DefaultMIDTag + "\n8000000001,m1,814,400081203\n8000044499,m1,814,400081204\n"
+	if err := os.WriteFile(manifest, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runCmd(t, srv, "terminals", "onboard", "-manifest", manifest, "-report", report, "-checkpoint", filepath.Join(dir, "cp.jsonl"))
+	if code != exitOK {
+		t.Fatalf("terminals onboard exited %d:\n%s%s", code, out, errOut)
+	}
+	if !strings.Contains(out, "created") || !strings.Contains(out, "skipped") {
+		t.Errorf("terminals onboard output:\n%s", out)
+	}
+	b, err := os.ReadFile(report)
+	if err != nil || !strings.Contains(string(b), "8000000001,created") {
+		t.Errorf("report got %s, error %v", b, err)
+	}
+}
+
+func TestDriftCommand(t *testing.T) {
+	srv := newTestServer(t)
+	term, _ := srv.Terminal(25)
+	term.Params = map[string]string{amp360test.DefaultMIDTag: "400081203"}
+	srv.Seed(amp360test.Data{Terminals: []amp360test.Terminal{term}})
+
+	code, out, errOut := runCmd(t, srv, "-o", "csv", "templates", "drift", "814", "-hidden")
+	if code != exitOK {
+		t.Fatalf("templates drift exited %d:\n%s%s", code, out, errOut)
+	}
+	want := "CATEGORY,TERMINAL,SERIAL,TAG,TEMPLATE,TERMINAL VALUE\nTERMINAL,25,8000044499," + amp360test.DefaultMIDTag + ",000000000,400081203\n"
+	if out != want {
+		t.Errorf("templates drift got:\n%s\nwant:\n%s", out, want)
+	}
+
+	code, out, _ = runCmd(t, srv, "-o", "json", "templates", "drift", "814")
+	if code != exitOK || !strings.Contains(out, `"drifts": []`) {
+		t.Errorf("templates drift without hidden parameters exited %d:\n%s", code, out)
+	}
+}
+
+func TestReconcileCommands(t *testing.T) {
+	srv := newTestServer(t)
+	state := filepath.Join(t.TempDir(), "fleet.yaml")
+	content := "terminals:\n  - serial: \"8000044499\"\n    name: Shop 1\n  - serial: \"8000000001\"\n    model: m1\n    template: \"814\"\n"
+	if err := os.WriteFile(state, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runCmd(t, srv, "reconcile", "plan", "-f", state)
+	if code != exitOK || !strings.Contains(out, "Plan: 1 to create, 1 to update, 0 to change parameters, 0 to delete.") {
+		t.Fatalf("reconcile plan exited %d:\n%s%s", code, out, errOut)
+	}
+
+	code, out, errOut = runCmd(t, srv, "reconcile", "apply", "-f", state)
+	if code != exitOK || strings.Count(out, "applied") != 2 {
+		t.Fatalf("reconcile apply exited %d:\n%s%s", code, out, errOut)
+	}
+	if term, _ := srv.Terminal(25); term.Name != "Shop 1" {
+		t.Errorf("Name got %v, want %v", term.Name, "Shop 1")
+	}
+
+	code, out, _ = runCmd(t, srv, "reconcile", "plan", "-f", state)
+	if code != exitOK || !strings.Contains(out, "No changes.") {
+		t.Errorf("second reconcile plan exited %d:\n%s", code, out)
+	}
+}
+
+func TestTemplatesExportImport(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed(amp360test.Data{Templates: []amp360test.Template{{
+		ID:     902,
+		Params: []amp360.Param{{Tag: amp360test.DefaultMIDTag, Type: "STRING", Value: "1", CategoryName: "TERMINAL"}},
+	}}})
+	file := filepath.Join(t.TempDir(), "apitest.yaml")
+
+	if code, _, errOut := runCmd(t, srv, "templates", "export", "814", "-f", file); code != exitOK {
+		t.Fatalf("templates export exited %d: %s", code, errOut)
+	}
+	code, out, errOut := runCmd(t, srv, "templates", "import", "902", "-f", file, "-dry-run")
+	if code != exitOK || !strings.Contains(out, "000000000") {
+		t.Fatalf("templates import -dry-run exited %d:\n%s%s", code, out, errOut)
+	}
+	if tmpl, _ := srv.Template(902); tmpl.Params[0].Value != "1" {
+		t.Error("dry run updated the template")
+	}
+	if code, _, errOut := runCmd(t, srv, "templates", "import", "902", "-f", file); code != exitOK {
+		t.Fatalf("templates import exited %d: %s", code, errOut)
+	}
+	if tmpl, _ := srv.Template(902); tmpl.Params[0].Value != "000000000" {
+		t.Errorf("Value got %v, want %v", tmpl.Params[0].Value, "000000000")
+	}
+}
+
+func TestApplicationsCommands(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed(amp360test.Data{
+		Applications: []amp360test.Application{
+			{ID: "a1", Name: "POS", Version: "1.0.0"},
+			{ID: "a2", Name: "POS", Version: "1.1.0"},
+		},
+		Templates: []amp360test.Template{
+			{ID: 903, Name: "POS", Applications: []string{"a1"}},
+			{ID: 904, Name: "POS NEW", Applications: []string{"a2"}},
+		},
+	})
+
+	code, out, _ := runCmd(t, srv, "-o", "csv", "applications", "usage", "POS")
+	if want := "VERSION,TEMPLATE ID,TEMPLATE\n1.0.0,903,POS\n1.1.0,904,POS NEW\n"; code != exitOK || out != want {
+		t.Errorf("applications usage got:\n%s\nwant:\n%s", out, want)
+	}
+	code, out, _ = runCmd(t, srv, "-o", "csv", "applications", "usage", "POS", "-version", "1.1.0")
+	if want := "VERSION,TEMPLATE ID,TEMPLATE\n1.1.0,904,POS NEW\n"; code != exitOK || out != want {
+		t.Errorf("applications usage -version got:\n%s\nwant:\n%s", out, want)
+	}
+	if code, _, _ := runCmd(t, srv, "applications", "usage"); code != exitUsage {
+		t.Errorf("applications usage without a name exited %d, want %d", code, exitUsage)
+	}
+}
+
+func TestFirmwareCommands(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed(amp360test.Data{
+		Firmware: []amp360test.Firmware{
+			{ID: "f0", Name: "AMP8000-2AA", Version: "03.02.38", ModelID: "m1"},
+			{ID: "f1", Name: "AMP8000-2AA", Version: "03.02.39", ModelID: "m1", IsLatest: true},
+		},
+		Terminals: []amp360test.Terminal{
+			{ID: 25, SerialNumber: "8000044499", Name: "T1", TemplateID: 814, ModelID: "m1", FirmwareID: "f1"},
+			{ID: 26, SerialNumber: "8000044500", TemplateID: 814, ModelID: "m1", FirmwareID: "f0", QueueFirmware: true},
+		},
+	})
+
+	code, out, errOut := runCmd(t, srv, "-o", "csv", "firmware", "compliance", "-outdated")
+	want := "MODEL,FIRMWARE,VERSION,LATEST,TERMINAL,SERIAL,QUEUED\nAMP8000,AMP8000-2AA,03.02.38,no,26,8000044500,yes\n"
+	if code != exitOK || out != want {
+		t.Errorf("firmware compliance exited %d:\n%s%s\nwant:\n%s", code, out, errOut, want)
+	}
+}
+
+func TestCompaniesCommands(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed(amp360test.Data{Companies: []amp360.Company{
+		{ID: "client1", Name: "RESELLER", Type: "RESELLER"},
+		{ID: "client2", Name: "SHOP 1", ParentID: "client1"},
+		{ID: "client3", Name: "OUTLET", ParentID: "client2"},
+	}})
+
+	code, out, errOut := runCmd(t, srv, "-o", "csv", "companies", "tree")
+	want := "ID,TYPE,NAME,PATH\nclient1,RESELLER,RESELLER,RESELLER\nclient2,,\"  SHOP 1\",RESELLER/SHOP 1\nclient3,,\"    OUTLET\",RESELLER/SHOP 1/OUTLET\n"
+	if code != exitOK || out != want {
+		t.Errorf("companies tree exited %d:\n%s%s\nwant:\n%s", code, out, errOut, want)
+	}
+}
+
+func TestWatchCommand(t *testing.T) {
+	srv := newTestServer(t)
+	store := filepath.Join(t.TempDir(), "fleet.json")
+
+	if code, out, errOut := runCmd(t, srv, "terminals", "watch", "-once", "-store", store); code != exitOK || out != "" {
+		t.Fatalf("baseline watch exited %d:\n%s%s", code, out, errOut)
+	}
+	srv.Seed(amp360test.Data{Terminals: []amp360test.Terminal{{ID: 40, SerialNumber: "8000000040"}}})
+	code, out, errOut := runCmd(t, srv, "-o", "json", "terminals", "watch", "-once", "-store", store)
+	if code != exitOK || !strings.Contains(out, `"type":"terminal.created"`) || !strings.Contains(out, "8000000040") {
+		t.Errorf("watch exited %d:\n%s%s", code, out, errOut)
+	}
+}
+
+func TestAPIKeyFile(t *testing.T) {
+	srv := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"AMP360_ENV": srv.URL + amp360test.BasePath, "AMP360_API_KEY_FILE": path}
+	code := run([]string{"models", "list"}, &bytes.Buffer{}, &bytes.Buffer{}, func(k string) string { return env[k] })
+	if code != exitOK {
+		t.Errorf("models list with a key file exited %d", code)
+	}
+}
+
+func TestTenants(t *testing.T) {
+	srv := newTestServer(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	content := "tenants:\n  - name: acq1\n    api_key: key\n  - name: acq2\n    api_key: wrong\n"
+	if err := os.WriteFile(config, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"AMP360_ENV": srv.URL + amp360test.BasePath, "AMP360_CONFIG": config}
+	runTenants := func(args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		code := run(args, out, &bytes.Buffer{}, func(k string) string { return env[k] })
+		return code, out.String()
+	}
+
+	if code, out := runTenants("-tenant", "acq1", "terminals", "list"); code != exitOK || !strings.Contains(out, "8000044499") {
+		t.Errorf("terminals list as acq1 exited %d:\n%s", code, out)
+	}
+	if code, _ := runTenants("-tenant", "acq2", "terminals", "list"); code != exitAuth {
+		t.Errorf("terminals list as acq2 exited %d, want %d", code, exitAuth)
+	}
+	if code, _ := runTenants("-tenant", "acq3", "terminals", "list"); code != exitUsage {
+		t.Errorf("unknown tenant exited %d, want %d", code, exitUsage)
+	}
+	code, out := runTenants("-o", "json", "terminals", "list", "-all-tenants")
+	if code != exitAuth || !strings.Contains(out, `"tenant": "acq1"`) {
+		t.Errorf("terminals list -all-tenants exited %d:\n%s", code, out)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := newTestServer(t)
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	code, recorded, errOut := runCmd(t, srv, "-record", cassette, "terminals", "list")
+	if code != exitOK {
+		t.Fatalf("terminals list -record exited %d: %s", code, errOut)
+	}
+	b, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "REDACTED") || strings.Contains(string(b), `"Authorization":["key"]`) {
+		t.Errorf("API key not scrubbed from the cassette:\n%s", b)
+	}
+
+	srv.Close()
+	code, replayed, errOut := runCmd(t, srv, "-replay", cassette, "terminals", "list")
+	if code != exitOK || replayed != recorded {
+		t.Errorf("terminals list -replay exited %d:\n%s%s", code, replayed, errOut)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/andrei-cloud/amp360/onboard"
+)
+
+func (a *app) terminalsOnboard(args []string) error {
+	fs := a.flagSet("terminals onboard")
+	manifest := fs.String("manifest", "", "CSV or XLSX manifest of the terminals")
+	workers := fs.Int("workers", 4, "number of concurrent workers")
+	conflict := fs.String("on-conflict", "skip", "existing serial numbers: skip, update or fail")
+	checkpoint := fs.String("checkpoint", "", "checkpoint file used to resume an interrupted run")
+	report := fs.String("report", "", "write the per-row CSV report to this file")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *manifest == "" {
+		return fmt.Errorf("%w: -manifest is required", errUsage)
+	}
+	policy, err := onboard.ParsePolicy(*conflict)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	rows, err := onboard.ReadFile(*manifest)
+	if err != nil {
+		return err
+	}
+	o := &onboard.Onboarder{
+		Client:     a.client,
+		Workers:    *workers,
+		OnConflict: policy,
+		Checkpoint: *checkpoint,
+		Progress: func(r onboard.Result) {
+			fmt.Fprintf(a.stderr, "line %d %s: %s %s\n", r.Line, r.SerialNumber, r.Status, r.Error)
+		},
+	}
+	results, runErr := o.Run(context.Background(), rows)
+
+	if *report != "" {
+		f, err := os.Create(*report)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := onboard.WriteReport(f, results); err != nil {
+			return err
+		}
+	}
+
+	table := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		if r.Status == onboard.Failed || r.Status == onboard.Invalid {
+			failed++
+		}
+		table = append(table, []string{strconv.Itoa(r.Line), r.SerialNumber, string(r.Status), strconv.Itoa(r.TerminalID), r.Error})
+	}
+	if err := a.out.print(results, []string{"LINE", "SERIAL", "STATUS", "TERMINAL", "ERROR"}, table); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(results))
+	}
+	return nil
+}
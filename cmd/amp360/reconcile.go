@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/andrei-cloud/amp360/reconcile"
+)
+
+func (a *app) reconcilePlan(args []string) error {
+	r, s, err := a.reconciler("reconcile plan", args)
+	if err != nil {
+		return err
+	}
+	p, err := r.Plan(context.Background(), s)
+	if err != nil {
+		return err
+	}
+	return a.printPlan(p)
+}
+
+func (a *app) reconcileApply(args []string) error {
+	r, s, err := a.reconciler("reconcile apply", args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	p, err := r.Plan(ctx, s)
+	if err != nil {
+		return err
+	}
+	if a.out.format == "table" {
+		if err := p.Write(a.stderr); err != nil {
+			return err
+		}
+	}
+	if p.Empty() {
+		return nil
+	}
+
+	r.Progress = func(res reconcile.Result) {
+		fmt.Fprintf(a.stderr, "%s %s: %s %s\n", res.Action, res.SerialNumber, res.Status, res.Error)
+	}
+	results, runErr := r.Apply(ctx, p)
+
+	table := make([][]string, 0, len(results))
+	failed := 0
+	for _, res := range results {
+		if res.Status == reconcile.Failed {
+			failed++
+		}
+		table = append(table, []string{string(res.Action), res.SerialNumber, strconv.Itoa(res.TerminalID), string(res.Status), res.Error})
+	}
+	if err := a.out.print(results, []string{"ACTION", "SERIAL", "TERMINAL", "STATUS", "ERROR"}, table); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d actions failed", failed, len(results))
+	}
+	return nil
+}
+
+func (a *app) reconciler(name string, args []string) (*reconcile.Reconciler, *reconcile.State, error) {
+	fs := a.flagSet(name)
+	file := fs.String("f", "", "YAML or JSON desired state of the terminals")
+	r := &reconcile.Reconciler{Client: a.client}
+	fs.BoolVar(&r.Prune, "prune", false, "delete the terminals missing from the desired state")
+	fs.IntVar(&r.Workers, "workers", 4, "number of concurrent workers")
+	if _, err := parseFlags(fs, args); err != nil {
+		return nil, nil, err
+	}
+	if *file == "" {
+		return nil, nil, fmt.Errorf("%w: -f is required", errUsage)
+	}
+	s, err := reconcile.ReadFile(*file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, s, nil
+}
+
+func (a *app) printPlan(p *reconcile.Plan) error {
+	if a.out.format == "table" {
+		return p.Write(a.out.w)
+	}
+	var table [][]string
+	for _, act := range p.Actions {
+		for _, c := range act.Changes {
+			table = append(table, []string{string(act.Type), act.SerialNumber, strconv.Itoa(act.TerminalID), c.Field, c.From, c.To})
+		}
+	}
+	return a.out.print(p, []string{"ACTION", "SERIAL", "TERMINAL", "FIELD", "FROM", "TO"}, table)
+}
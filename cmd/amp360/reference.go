@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/bundle"
+)
+
+func (a *app) templatesList(args []string) error {
+	if _, err := parseFlags(a.flagSet("templates list"), args); err != nil {
+		return err
+	}
+	templates, err := amp360.Collect(a.client.TemplatesService.All(context.Background(), nil), 0)
+	if err != nil {
+		return err
+	}
+	table := make([][]string, 0, len(templates))
+	for _, t := range templates {
+		apps := ""
+		for i, app := range t.Applications {
+			if i > 0 {
+				apps += ", "
+			}
+			apps += app.Name + " " + app.Version
+		}
+		table = append(table, []string{strconv.Itoa(t.ID), t.Name, t.Client.Name, apps})
+	}
+	return a.out.print(templates, []string{"ID", "NAME", "CLIENT", "APPLICATIONS"}, table)
+}
+
+func (a *app) templatesParams(args []string) error {
+	fs := a.flagSet("templates params")
+	category := fs.String("category", "", "filter by category ID")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := templateID(args)
+	if err != nil {
+		return err
+	}
+
+	var opt *amp360.ParamsOpt
+	if *category != "" {
+		opt = &amp360.ParamsOpt{CategoryId: *category}
+	}
+	tp, err := a.client.TemplatesService.Params(context.Background(), id, opt)
+	if err != nil {
+		return err
+	}
+	table := make([][]string, 0, len(tp.Rows))
+	for _, p := range tp.Rows {
+		table = append(table, []string{p.CategoryName, p.Tag, p.Type, p.Value, p.DefaultValue})
+	}
+	return a.out.print(tp, []string{"CATEGORY", "TAG", "TYPE", "VALUE", "DEFAULT"}, table)
+}
+
+func (a *app) companiesList(args []string) error {
+	if _, err := parseFlags(a.flagSet("companies list"), args); err != nil {
+		return err
+	}
+	companies, err := amp360.Collect(a.client.CompaniesService.All(context.Background(), nil), 0)
+	if err != nil {
+		return err
+	}
+	table := make([][]string, 0, len(companies))
+	for _, c := range companies {
+		table = append(table, []string{c.ID, c.Name, c.Type})
+	}
+	return a.out.print(companies, []string{"ID", "NAME", "TYPE"}, table)
+}
+
+func (a *app) modelsList(args []string) error {
+	if _, err := parseFlags(a.flagSet("models list"), args); err != nil {
+		return err
+	}
+	ml, err := a.client.ModelsService.List(context.Background())
+	if err != nil {
+		return err
+	}
+	table := make([][]string, 0, len(ml.Rows))
+	for _, m := range ml.Rows {
+		table = append(table, []string{m.ID, m.Name, m.HardwareID, m.JointName})
+	}
+	return a.out.print(ml.Rows, []string{"ID", "NAME", "HARDWARE", "JOINT NAME"}, table)
+}
+
+func (a *app) templatesDrift(args []string) error {
+	fs := a.flagSet("templates drift")
+	opt := &amp360.DriftOpt{}
+	terminal := fs.Int("terminal", 0, "compare only this terminal")
+	serial := fs.String("serial", "", "compare only the terminal with this serial number")
+	fs.BoolVar(&opt.Editable, "editable", false, "include parameters editable on terminals")
+	fs.BoolVar(&opt.Hidden, "hidden", false, "include parameters not visible on terminals")
+	fs.IntVar(&opt.Workers, "workers", 4, "terminals compared concurrently")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := templateID(args)
+	if err != nil {
+		return err
+	}
+	if *terminal != 0 || *serial != "" {
+		opt.Terminals = &amp360.TerminalsOpt{ID: *terminal, SerialNumber: *serial}
+	}
+
+	r, err := a.client.TemplatesService.Drift(context.Background(), id, opt)
+	if err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(a.stderr, "amp360: terminal %d (%s): %s\n", e.TerminalID, e.SerialNumber, e.Error)
+	}
+	table := make([][]string, 0, len(r.Drifts))
+	for _, d := range r.Drifts {
+		table = append(table, []string{d.Category, strconv.Itoa(d.TerminalID), d.SerialNumber, d.Tag, d.Expected, d.Actual})
+	}
+	return a.out.print(r, []string{"CATEGORY", "TERMINAL", "SERIAL", "TAG", "TEMPLATE", "TERMINAL VALUE"}, table)
+}
+
+func (a *app) templatesExport(args []string) error {
+	fs := a.flagSet("templates export")
+	file := fs.String("f", "", "bundle file, JSON if it ends in .json and YAML otherwise")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: expected a template ID and -f", errUsage)
+	}
+	id, err := templateID(args)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Export(context.Background(), a.client, id, *file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "exported %d parameters of template %d to %s\n", len(b.Params), id, *file)
+	return nil
+}
+
+func (a *app) templatesImport(args []string) error {
+	fs := a.flagSet("templates import")
+	file := fs.String("f", "", "bundle file written by templates export")
+	dryRun := fs.Bool("dry-run", false, "only show the changes")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: expected a template ID and -f", errUsage)
+	}
+	id, err := templateID(args)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	res, err := b.Import(context.Background(), a.client, id, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	table := make([][]string, 0, len(res.Changes))
+	for _, ch := range res.Changes {
+		note := ""
+		switch {
+		case ch.Missing:
+			note = "missing"
+		case ch.File:
+			note = "file"
+		}
+		table = append(table, []string{ch.Category, ch.Tag, ch.From, ch.To, note})
+	}
+	if err := a.out.print(res, []string{"CATEGORY", "TAG", "FROM", "TO", ""}, table); err != nil {
+		return err
+	}
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("parameters not updated: %v", res.Failed)
+	}
+	return nil
+}
+
+func templateID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: expected a template ID", errUsage)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid template ID %q", errUsage, args[0])
+	}
+	return id, nil
+}
+
+func (a *app) companiesTree(args []string) error {
+	fs := a.flagSet("companies tree")
+	opt := &amp360.TreeOpt{}
+	fs.IntVar(&opt.MaxDepth, "depth", 0, "levels fetched, all if zero")
+	fs.IntVar(&opt.Workers, "workers", 4, "sub-company lists fetched concurrently")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	tree, err := a.client.CompaniesService.Tree(context.Background(), opt)
+	if err != nil {
+		return err
+	}
+	var table [][]string
+	tree.Walk(func(n *amp360.CompanyNode) error {
+		name := strings.Repeat("  ", n.Depth()-1) + n.Name
+		table = append(table, []string{n.ID, n.Type, name, n.Path()})
+		return nil
+	})
+	return a.out.print(tree, []string{"ID", "TYPE", "NAME", "PATH"}, table)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/andrei-cloud/amp360"
+)
+
+// terminalRow is the printed form of a terminal.
+type terminalRow struct {
+	ID           int    `json:"id"`
+	SerialNumber string `json:"serialNumber"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	TemplateID   int    `json:"templateId"`
+	Template     string `json:"template"`
+	ClientID     string `json:"clientId"`
+	ModelID      string `json:"modelId"`
+	FirmwareID   string `json:"firmwareId"`
+}
+
+func newTerminalRow(t *amp360.Terminal) terminalRow {
+	return terminalRow{
+		ID:           t.ID,
+		SerialNumber: t.SerialNumber,
+		Name:         t.Name,
+		Status:       t.Status,
+		TemplateID:   t.AppTemplateID,
+		Template:     t.AppTemplate.Name,
+		ClientID:     t.ClientID,
+		ModelID:      t.TerminalModelID,
+		FirmwareID:   t.FirmwareID,
+	}
+}
+
+func (a *app) printTerminals(terminals []amp360.Terminal) error {
+	rows := make([]terminalRow, 0, len(terminals))
+	table := make([][]string, 0, len(terminals))
+	for i := range terminals {
+		r := newTerminalRow(&terminals[i])
+		rows = append(rows, r)
+		table = append(table, []string{strconv.Itoa(r.ID), r.SerialNumber, r.Name, r.Status, r.Template, r.ClientID})
+	}
+	return a.out.print(rows, []string{"ID", "SERIAL", "NAME", "STATUS", "TEMPLATE", "CLIENT"}, table)
+}
+
+// tenantTerminalRow is the printed form of a terminal of a tenant.
+type tenantTerminalRow struct {
+	Tenant string `json:"tenant"`
+	terminalRow
+}
+
+func (a *app) tenantsTerminals(ctx context.Context, opt *amp360.TerminalsOpt) error {
+	if a.pool == nil {
+		return fmt.Errorf("%w: no tenants in the config file", errUsage)
+	}
+	terminals, err := a.pool.Terminals(ctx, opt)
+	if err != nil {
+		// print what the other tenants returned
+		fmt.Fprintf(a.stderr, "amp360: %v\n", err)
+	}
+	rows := make([]tenantTerminalRow, 0, len(terminals))
+	table := make([][]string, 0, len(terminals))
+	for _, tt := range terminals {
+		r := tenantTerminalRow{Tenant: tt.Tenant, terminalRow: newTerminalRow(tt.Terminal)}
+		rows = append(rows, r)
+		table = append(table, []string{r.Tenant, strconv.Itoa(r.ID), r.SerialNumber, r.Name, r.Status, r.Template, r.ClientID})
+	}
+	if perr := a.out.print(rows, []string{"TENANT", "ID", "SERIAL", "NAME", "STATUS", "TEMPLATE", "CLIENT"}, table); perr != nil {
+		return perr
+	}
+	return err
+}
+
+func terminalID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: expected a terminal ID", errUsage)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid terminal ID %q", errUsage, args[0])
+	}
+	return id, nil
+}
+
+func (a *app) terminalsList(args []string) error {
+	fs := a.flagSet("terminals list")
+	opt := &amp360.TerminalsOpt{}
+	fs.StringVar(&opt.SerialNumber, "serial", "", "filter by serial number")
+	fs.StringVar(&opt.TID, "tid", "", "filter by terminal ID parameter")
+	fs.StringVar(&opt.MID, "mid", "", "filter by merchant ID parameter")
+	fs.IntVar(&opt.Size, "size", 0, "page size")
+	fs.IntVar(&opt.Page, "page", 0, "page number")
+	all := fs.Bool("all", false, "fetch all pages")
+	max := fs.Int("max", 10000, "maximum number of terminals fetched with -all")
+	allTenants := fs.Bool("all-tenants", false, "list all the pages of every tenant of the config file")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if *allTenants {
+		return a.tenantsTerminals(ctx, opt)
+	}
+	if *all {
+		terminals, err := amp360.Collect(a.client.TerminalsService.All(ctx, opt), *max)
+		if err != nil {
+			return err
+		}
+		return a.printTerminals(terminals)
+	}
+	tl, err := a.client.TerminalsService.List(ctx, opt)
+	if err != nil {
+		return err
+	}
+	return a.printTerminals(tl.Rows)
+}
+
+func (a *app) terminalsGet(args []string) error {
+	fs := a.flagSet("terminals get")
+	serial := fs.String("serial", "", "serial number of the terminal")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	opt := &amp360.TerminalsOpt{SerialNumber: *serial}
+	if *serial == "" {
+		if opt.ID, err = terminalID(args); err != nil {
+			return err
+		}
+	}
+	d, err := a.client.TerminalsService.Details(context.Background(), opt)
+	if err != nil {
+		return err
+	}
+
+	t := &d.Terminal
+	apps := ""
+	for i, td := range d.TemplateDetails {
+		if i > 0 {
+			apps += ", "
+		}
+		apps += td.Application.Name + " " + td.Application.Version
+	}
+	return a.out.print(d, []string{"FIELD", "VALUE"}, [][]string{
+		{"ID", strconv.Itoa(t.ID)},
+		{"Serial", t.SerialNumber},
+		{"Name", t.Name},
+		{"Status", t.Status},
+		{"Model", t.TerminalModel.Name},
+		{"Firmware", t.Firmware.Version},
+		{"Template", strconv.Itoa(t.AppTemplateID)},
+		{"Client", t.ClientID},
+		{"Applications", apps},
+	})
+}
+
+func (a *app) terminalsCreate(args []string) error {
+	fs := a.flagSet("terminals create")
+	nt := &amp360.NewTerminal{}
+	var model, client string
+	fs.StringVar(&nt.SerialNumber, "serial", "", "serial number")
+	fs.StringVar(&nt.Name, "name", "", "terminal name")
+	fs.StringVar(&model, "model", "", "terminal model ID")
+	fs.StringVar(&client, "client", "", "client ID")
+	fs.StringVar(&nt.TemplateID, "template", "", "template ID")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if nt.SerialNumber == "" || model == "" {
+		return fmt.Errorf("%w: -serial and -model are required", errUsage)
+	}
+	nt.ModelID = model
+	if client != "" {
+		nt.ClientID = client
+	}
+	params, err := keyValues(args)
+	if err != nil {
+		return err
+	}
+	nt.Parameters = map[string]interface{}{}
+	for k, v := range params {
+		nt.Parameters[k] = v
+	}
+
+	ct, err := a.client.TerminalsService.Add(context.Background(), nt)
+	if err != nil {
+		return err
+	}
+	return a.out.print(ct, []string{"ID", "SERIAL", "NAME", "STATUS"}, [][]string{
+		{strconv.Itoa(ct.ID), ct.SerialNumber, ct.Name, ct.Status},
+	})
+}
+
+func (a *app) terminalsUpdate(args []string) error {
+	fs := a.flagSet("terminals update")
+	name := fs.String("name", "", "new terminal name")
+	client := fs.String("client", "", "new client ID")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := terminalID(args)
+	if err != nil {
+		return err
+	}
+	if *name == "" && *client == "" {
+		return fmt.Errorf("%w: nothing to update", errUsage)
+	}
+
+	upd := &amp360.TerminalUpdate{Name: *name, ClientID: *client}
+	return a.client.TerminalsService.Edit(context.Background(), id, upd)
+}
+
+func (a *app) terminalsDelete(args []string) error {
+	args, err := parseFlags(a.flagSet("terminals delete"), args)
+	if err != nil {
+		return err
+	}
+	id, err := terminalID(args)
+	if err != nil {
+		return err
+	}
+	return a.client.TerminalsService.Delete(context.Background(), id)
+}
+
+func (a *app) terminalsParamsGet(args []string) error {
+	fs := a.flagSet("terminals params get")
+	category := fs.String("category", "", "filter by category ID")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := terminalID(args)
+	if err != nil {
+		return err
+	}
+
+	var opt *amp360.ParamsOpt
+	if *category != "" {
+		opt = &amp360.ParamsOpt{CategoryId: *category}
+	}
+	tp, err := a.client.TerminalsService.Params(context.Background(), id, opt)
+	if err != nil {
+		return err
+	}
+	table := make([][]string, 0, len(tp.Rows))
+	for _, p := range tp.Rows {
+		table = append(table, []string{p.CategoryName, p.Tag, p.Type, p.Value, p.DefaultValue})
+	}
+	return a.out.print(tp, []string{"CATEGORY", "TAG", "TYPE", "VALUE", "DEFAULT"}, table)
+}
+
+func (a *app) terminalsParamsSet(args []string) error {
+	fs := a.flagSet("terminals params set")
+	var files multiFlag
+	fs.Var(&files, "file", "file parameter as key=path, may be repeated")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%w: expected a terminal ID", errUsage)
+	}
+	id, err := terminalID(args[:1])
+	if err != nil {
+		return err
+	}
+	params, err := keyValues(args[1:])
+	if err != nil {
+		return err
+	}
+	paramfiles, err := keyValues(files)
+	if err != nil {
+		return err
+	}
+	if len(params) == 0 && len(paramfiles) == 0 {
+		return fmt.Errorf("%w: nothing to update", errUsage)
+	}
+
+	res, err := a.client.TerminalsService.SetParams(context.Background(), id, params, paramfiles)
+	if err != nil {
+		return err
+	}
+	return a.printBulk(res)
+}
+
+func (a *app) printBulk(res *amp360.BulkResult) error {
+	table := [][]string{}
+	for _, p := range res.Updated {
+		table = append(table, []string{p, "updated"})
+	}
+	for _, p := range res.Failed {
+		table = append(table, []string{p, "failed"})
+	}
+	return a.out.print(res, []string{"PARAMETER", "RESULT"}, table)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/andrei-cloud/amp360/watch"
+)
+
+func (a *app) terminalsWatch(args []string) error {
+	fs := a.flagSet("terminals watch")
+	w := &watch.Watcher{Client: a.client}
+	store := fs.String("store", "", "file keeping the last snapshot between runs")
+	fs.DurationVar(&w.Interval, "interval", 5*time.Minute, "time between polls")
+	fs.BoolVar(&w.Details, "details", false, "fetch the firmware versions of changed terminals")
+	once := fs.Bool("once", false, "poll once and exit")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *store != "" {
+		w.Store = &watch.FileStore{Path: *store}
+	}
+
+	enc := json.NewEncoder(a.out.w)
+	emit := func(ev watch.Event) {
+		if a.out.format == "json" {
+			enc.Encode(ev)
+			return
+		}
+		fmt.Fprintf(a.out.w, "%s %s\n", ev.Time.Format(time.RFC3339), ev)
+	}
+
+	if *once {
+		events, err := w.Poll(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			emit(ev)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	w.OnEvent = emit
+	w.OnError = func(err error) {
+		fmt.Fprintf(a.stderr, "amp360: %v\n", err)
+	}
+	w.Run(ctx)
+	return nil
+}
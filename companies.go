@@ -14,17 +14,32 @@ type CompaniesList struct {
 }
 
 type Company struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Type string `json:"type"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	ParentID string `json:"parentId,omitempty"`
+	// OriginPath is the path of the company reported by the server, the
+	// OriginPath of the TemplateClient of its templates.
+	OriginPath string `json:"originPath,omitempty"`
 }
 
 type CompaniesOpt struct {
-	Size int `url:"size"`
-	Page int `url:"page"`
+	// ParentID lists the sub-companies of this company rather than those of
+	// the client.
+	ParentID string `url:"parentId,omitempty"`
+	Size     int    `url:"size"`
+	Page     int    `url:"page"`
 }
 
+// List returns the sub-companies of the client, or of opt.ParentID.
+func (c *CompaniesService) List(ctx context.Context, opt *CompaniesOpt) (*CompaniesList, error) {
+	ctx = withOperation(ctx, OpCompaniesList, "CompaniesService.List")
+	return fetchList[CompaniesList](ctx, c.client, "client/children", opt)
+}
+
+// Deprecated: use List.
 func (c *CompaniesService) GetList(ctx context.Context, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpCompaniesList, "CompaniesService.GetList")
 	path := "client/children"
 	var url *url.URL
 	if url, err = addOptions(path, opt); err != nil {
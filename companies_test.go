@@ -49,3 +49,24 @@ func BenchmarkCompaniesGetListMock(b *testing.B) {
 		}
 	}
 }
+
+func TestCompaniesListMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if got := r.URL.Query().Get("size"); got != "10" {
+			t.Errorf("size query got %v, want %v", got, "10")
+		}
+		fmt.Fprint(w, `{"success":true,"message":"Successfully fetched sub-clients.","data":{"count":2,"rows":[{"id":"test1","name":"TEST 1","type":"MERCHANT"},{"id":"test2","name":"TEST2","type":"MERCHANT"}]}}`)
+	})
+
+	cl, err := c.CompaniesService.List(context.Background(), &CompaniesOpt{Size: 10, Page: 1})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if cl.Count != 2 || cl.Rows[1].ID != "test2" {
+		t.Errorf("Companies got %+v", cl)
+	}
+}
@@ -0,0 +1,319 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CompanyNode is a company of a CompanyTree.
+type CompanyNode struct {
+	Company
+	Parent   *CompanyNode   `json:"-"` // nil for the sub-companies of the client
+	Children []*CompanyNode `json:"children,omitempty"`
+}
+
+// Path returns the names of the companies from the top of the tree down to
+// n, separated by "/". A "/" or "\" in a name is escaped with a "\".
+func (n *CompanyNode) Path() string {
+	name := pathEscaper.Replace(n.Name)
+	if n.Parent == nil {
+		return name
+	}
+	return n.Parent.Path() + "/" + name
+}
+
+var pathEscaper = strings.NewReplacer(`\`, `\\`, `/`, `\/`)
+
+// splitPath returns the unescaped names of a path as returned by Path,
+// ignoring a leading and a trailing "/".
+func splitPath(path string) []string {
+	var (
+		names []string
+		name  strings.Builder
+	)
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			name.WriteByte(path[i])
+		case path[i] == '/':
+			names = append(names, name.String())
+			name.Reset()
+		default:
+			name.WriteByte(path[i])
+		}
+	}
+	names = append(names, name.String())
+	if len(names) > 1 && names[0] == "" {
+		names = names[1:]
+	}
+	if len(names) > 1 && names[len(names)-1] == "" {
+		names = names[:len(names)-1]
+	}
+	return names
+}
+
+// is reports whether n is at the path of names.
+func (n *CompanyNode) is(names []string) bool {
+	for i := len(names) - 1; i >= 0; i-- {
+		if n == nil || n.Name != names[i] {
+			return false
+		}
+		n = n.Parent
+	}
+	return n == nil
+}
+
+// Depth returns the level of n in the tree, 1 for the sub-companies of the
+// client.
+func (n *CompanyNode) Depth() int {
+	d := 1
+	for p := n.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
+
+// Walk calls fn for n and its descendants, parents first. It stops at the
+// first error returned by fn.
+func (n *CompanyNode) Walk(fn func(*CompanyNode) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompanyTree is the hierarchy of the sub-companies of the client. The
+// children of every company are sorted by name.
+type CompanyTree struct {
+	Roots []*CompanyNode `json:"companies"`
+}
+
+// Walk calls fn for every company of the tree, parents first. It stops at
+// the first error returned by fn.
+func (t *CompanyTree) Walk(fn func(*CompanyNode) error) error {
+	for _, n := range t.Roots {
+		if err := n.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Len returns the number of companies of the tree.
+func (t *CompanyTree) Len() int {
+	var count int
+	t.Walk(func(*CompanyNode) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+// ByID returns company id.
+func (t *CompanyTree) ByID(id string) (*CompanyNode, bool) {
+	return t.find(func(n *CompanyNode) bool { return n.ID == id })
+}
+
+// ByPath returns the company at path, the names of the companies from the
+// top of the tree separated by "/" as returned by CompanyNode.Path.
+func (t *CompanyTree) ByPath(path string) (*CompanyNode, bool) {
+	names := splitPath(path)
+	return t.find(func(n *CompanyNode) bool { return n.is(names) })
+}
+
+// ByName returns the companies named name.
+func (t *CompanyTree) ByName(name string) []*CompanyNode {
+	var nodes []*CompanyNode
+	t.Walk(func(n *CompanyNode) error {
+		if n.Name == name {
+			nodes = append(nodes, n)
+		}
+		return nil
+	})
+	return nodes
+}
+
+// ByOriginPath returns the company whose OriginPath, as reported by the
+// server, is path.
+func (t *CompanyTree) ByOriginPath(path string) (*CompanyNode, bool) {
+	if path == "" {
+		return nil, false
+	}
+	return t.find(func(n *CompanyNode) bool { return n.OriginPath == path })
+}
+
+// Resolve returns the company ref refers to: an ID, an origin path, a name
+// matching a single company or, if it contains "/" and no company has that
+// name, a path.
+func (t *CompanyTree) Resolve(ref string) (*CompanyNode, error) {
+	if n, ok := t.ByID(ref); ok {
+		return n, nil
+	}
+	if n, ok := t.ByOriginPath(ref); ok {
+		return n, nil
+	}
+	switch nodes := t.ByName(ref); len(nodes) {
+	case 0:
+		if strings.Contains(ref, "/") {
+			if n, ok := t.ByPath(ref); ok {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("company %q: %w", ref, ErrEntityNotFound)
+	case 1:
+		return nodes[0], nil
+	default:
+		paths := make([]string, len(nodes))
+		for i, n := range nodes {
+			paths[i] = n.Path()
+		}
+		return nil, fmt.Errorf("company name %q is ambiguous: %s", ref, strings.Join(paths, ", "))
+	}
+}
+
+var errStopWalk = errors.New("stop walk")
+
+// ErrRepeatedCompanies is returned by Tree when a list of sub-companies
+// holds a company already listed, as when the server ignores the parentId
+// filter. The hierarchy can't be built from such lists.
+var ErrRepeatedCompanies = errors.New("companies listed under several parents")
+
+func (t *CompanyTree) find(match func(*CompanyNode) bool) (*CompanyNode, bool) {
+	var found *CompanyNode
+	t.Walk(func(n *CompanyNode) error {
+		if match(n) {
+			found = n
+			return errStopWalk
+		}
+		return nil
+	})
+	return found, found != nil
+}
+
+// TreeOpt controls the walk of the company hierarchy.
+type TreeOpt struct {
+	// Workers is the number of sub-company lists fetched concurrently, 4 if
+	// zero.
+	Workers int
+	// MaxDepth is the number of levels fetched, all if zero.
+	MaxDepth int
+}
+
+// Tree walks the hierarchy of the sub-companies of the client, fetching the
+// sub-companies of every company. It fails on the first list that can't be
+// fetched, and with ErrRepeatedCompanies if a list repeats a company.
+func (c *CompaniesService) Tree(ctx context.Context, opt *TreeOpt) (*CompanyTree, error) {
+	if opt == nil {
+		opt = &TreeOpt{}
+	}
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		err  error
+		tree = &CompanyTree{}
+		seen = map[string]bool{}
+		sem  = make(chan struct{}, workers)
+	)
+	var expand func(parent *CompanyNode, depth int)
+	expand = func(parent *CompanyNode, depth int) {
+		defer wg.Done()
+		select {
+		case sem <- struct{}{}:
+		case <-wctx.Done():
+			return
+		}
+		o := &CompaniesOpt{}
+		if parent != nil {
+			o.ParentID = parent.ID
+		}
+		companies, ferr := Collect(c.All(wctx, o), 0)
+		<-sem
+
+		mu.Lock()
+		if ferr != nil {
+			if err == nil {
+				err = ferr
+				if parent != nil {
+					err = fmt.Errorf("sub-companies of %s: %w", parent.Path(), ferr)
+				}
+			}
+			mu.Unlock()
+			cancel()
+			return
+		}
+		var children []*CompanyNode
+		for _, co := range companies {
+			// A company listed twice means the server ignored parentId
+			// and listed the sub-companies of the client again.
+			if seen[co.ID] || (parent != nil && co.ID == parent.ID) {
+				if err == nil {
+					err = fmt.Errorf("company %s: %w", co.ID, ErrRepeatedCompanies)
+					if parent != nil {
+						err = fmt.Errorf("sub-companies of %s: %w", parent.Path(), err)
+					}
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			seen[co.ID] = true
+			if parent != nil {
+				co.ParentID = parent.ID
+			}
+			children = append(children, &CompanyNode{Company: co, Parent: parent})
+		}
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+		if parent == nil {
+			tree.Roots = children
+		} else {
+			parent.Children = children
+		}
+		mu.Unlock()
+
+		if opt.MaxDepth > 0 && depth >= opt.MaxDepth {
+			return
+		}
+		for _, n := range children {
+			wg.Add(1)
+			go expand(n, depth+1)
+		}
+	}
+	wg.Add(1)
+	expand(nil, 1)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Resolve walks the company hierarchy and returns the company ref refers
+// to, see CompanyTree.Resolve.
+func (c *CompaniesService) Resolve(ctx context.Context, ref string) (*CompanyNode, error) {
+	tree, err := c.Tree(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Resolve(ref)
+}
@@ -0,0 +1,181 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// companyHierarchy serves a four-level reseller hierarchy on mux.
+func companyHierarchy(mux *http.ServeMux, inflight, peak *int32) {
+	children := map[string]string{
+		"":   `[{"id":"r1","name":"RESELLER","type":"RESELLER"},{"id":"d1","name":"DIRECT","type":"MERCHANT"}]`,
+		"r1": `[{"id":"s2","name":"SUB B","type":"RESELLER"},{"id":"s1","name":"SUB A","type":"RESELLER"}]`,
+		"s1": `[{"id":"m1","name":"SHOP","type":"MERCHANT"}]`,
+		"s2": `[{"id":"m2","name":"SHOP","type":"MERCHANT"}]`,
+		"m1": `[{"id":"o1","name":"OUTLET 1","type":"OUTLET"}]`,
+		"m2": `[]`,
+		"d1": `[]`,
+		"o1": `[]`,
+	}
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		if n := atomic.AddInt32(inflight, 1); n > atomic.LoadInt32(peak) {
+			atomic.StoreInt32(peak, n)
+		}
+		defer atomic.AddInt32(inflight, -1)
+		time.Sleep(5 * time.Millisecond)
+
+		rows, ok := children[r.URL.Query().Get("parentId")]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"success":false,"message":"Internal error."}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success":true,"data":{"count":%d,"rows":%s}}`, strings.Count(rows, `"id"`), rows)
+	})
+}
+
+func TestCompaniesTreeMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	var inflight, peak int32
+	companyHierarchy(mux, &inflight, &peak)
+
+	tree, err := c.CompaniesService.Tree(context.Background(), &TreeOpt{Workers: 2})
+	if err != nil {
+		t.Fatalf("Tree returned error: %v", err)
+	}
+	if tree.Len() != 7 {
+		t.Errorf("Len = %d, want 7", tree.Len())
+	}
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Errorf("fetched %d lists concurrently, want at most 2", p)
+	}
+
+	var paths []string
+	tree.Walk(func(n *CompanyNode) error {
+		paths = append(paths, fmt.Sprintf("%d %s", n.Depth(), n.Path()))
+		return nil
+	})
+	want := []string{
+		"1 DIRECT",
+		"1 RESELLER",
+		"2 RESELLER/SUB A",
+		"3 RESELLER/SUB A/SHOP",
+		"4 RESELLER/SUB A/SHOP/OUTLET 1",
+		"2 RESELLER/SUB B",
+		"3 RESELLER/SUB B/SHOP",
+	}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("Walk = %q, want %q", paths, want)
+	}
+
+	outlet, ok := tree.ByPath("/RESELLER/SUB A/SHOP/OUTLET 1")
+	if !ok || outlet.ID != "o1" || outlet.Parent.ID != "m1" || outlet.ParentID != "m1" {
+		t.Errorf("ByPath = %+v", outlet)
+	}
+	if n, err := tree.Resolve("OUTLET 1"); err != nil || n.ID != "o1" {
+		t.Errorf("Resolve by name = %+v, %v", n, err)
+	}
+	if n, err := tree.Resolve("s2"); err != nil || n.Name != "SUB B" {
+		t.Errorf("Resolve by ID = %+v, %v", n, err)
+	}
+	if _, err := tree.Resolve("SHOP"); err == nil {
+		t.Error("Resolve of an ambiguous name returned no error")
+	}
+	if _, err := tree.Resolve("RESELLER/NONE"); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Resolve of a missing path returned %v", err)
+	}
+}
+
+func TestCompaniesTreeMaxDepthMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	var inflight, peak int32
+	companyHierarchy(mux, &inflight, &peak)
+
+	tree, err := c.CompaniesService.Tree(context.Background(), &TreeOpt{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("Tree returned error: %v", err)
+	}
+	if tree.Len() != 4 {
+		t.Errorf("Len = %d, want 4", tree.Len())
+	}
+	if n, err := c.CompaniesService.Resolve(context.Background(), "RESELLER/SUB B/SHOP"); err != nil || n.ID != "m2" {
+		t.Errorf("Resolve = %+v, %v", n, err)
+	}
+}
+
+func TestCompaniesTreeErrorMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("parentId") == "" {
+			fmt.Fprint(w, `{"success":true,"data":{"count":1,"rows":[{"id":"r1","name":"RESELLER"}]}}`)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"success":false,"message":"Internal error."}`)
+	})
+
+	if _, err := c.CompaniesService.Tree(context.Background(), nil); err == nil {
+		t.Error("Tree returned no error")
+	}
+}
+
+func TestCompaniesTreeSlashMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	children := map[string]string{
+		"":   `[{"id":"x1","name":"POS/ECR"},{"id":"p1","name":"POS"}]`,
+		"p1": `[{"id":"e1","name":"ECR"}]`,
+	}
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		rows, ok := children[r.URL.Query().Get("parentId")]
+		if !ok {
+			rows = `[]`
+		}
+		fmt.Fprintf(w, `{"success":true,"data":{"count":%d,"rows":%s}}`, strings.Count(rows, `"id"`), rows)
+	})
+
+	tree, err := c.CompaniesService.Tree(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Tree returned error: %v", err)
+	}
+	x1, _ := tree.ByID("x1")
+	if p := x1.Path(); p != `POS\/ECR` {
+		t.Errorf("Path = %q, want %q", p, `POS\/ECR`)
+	}
+	if n, ok := tree.ByPath(x1.Path()); !ok || n.ID != "x1" {
+		t.Errorf("ByPath of an escaped name = %+v", n)
+	}
+	if n, ok := tree.ByPath("POS/ECR"); !ok || n.ID != "e1" {
+		t.Errorf("ByPath = %+v", n)
+	}
+	// the exact name wins over the path
+	if n, err := tree.Resolve("POS/ECR"); err != nil || n.ID != "x1" {
+		t.Errorf("Resolve by name = %+v, %v", n, err)
+	}
+}
+
+func TestCompaniesTreeRepeatedMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	// a server ignoring parentId lists the sub-companies of the client again
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"count":2,"rows":[{"id":"r1","name":"RESELLER"},{"id":"d1","name":"DIRECT"}]}}`)
+	})
+
+	_, err := c.CompaniesService.Tree(context.Background(), nil)
+	if !errors.Is(err, ErrRepeatedCompanies) {
+		t.Errorf("Error got %v, want %v", err, ErrRepeatedCompanies)
+	}
+}
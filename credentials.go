@@ -0,0 +1,207 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned when a credentials provider has no API key.
+var ErrNoCredentials = errors.New("no API key available")
+
+// CredentialsProvider supplies the API key of every request. It must be
+// safe for concurrent use.
+type CredentialsProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialsRefresher is implemented by the providers caching their key.
+// When the server rejects a key with 401, Refresh is called with it before
+// the key is asked again, and the request is retried once if it changed.
+type CredentialsRefresher interface {
+	Refresh(ctx context.Context, rejected string) error
+}
+
+// SetCredentials makes the client take the API key of every request from p.
+// It may be called while requests are running.
+func (c *Client) SetCredentials(p CredentialsProvider) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.creds = p
+}
+
+// Credentials returns the credentials provider of the client, nil if none
+// was set.
+func (c *Client) Credentials() CredentialsProvider {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.creds
+}
+
+// apiKey returns the API key of the next request.
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	p := c.Credentials()
+	if p == nil {
+		return "", nil
+	}
+	key, err := p.APIKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("amp360: credentials: %w", err)
+	}
+	return key, nil
+}
+
+// authorize sets the API key of req, if it is sent to the host of the base
+// URL.
+func (c *Client) authorize(req *http.Request) error {
+	if req.URL.Host != c.BaseURL.Host {
+		return nil
+	}
+	key, err := c.apiKey(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", key)
+	return nil
+}
+
+// refreshKey tells whether a new API key should replace the key rejected by
+// a 401 response to req.
+func (c *Client) refreshKey(ctx context.Context, req *http.Request, res *http.Response) bool {
+	if res == nil || res.StatusCode != http.StatusUnauthorized || req.URL.Host != c.BaseURL.Host || !rewindable(req) {
+		return false
+	}
+	rejected := req.Header.Get("Authorization")
+	if r, ok := c.Credentials().(CredentialsRefresher); ok {
+		if err := r.Refresh(ctx, rejected); err != nil {
+			c.Logger().Warnf("amp360: refresh credentials: %v", err)
+			return false
+		}
+	}
+	key, err := c.apiKey(ctx)
+	return err == nil && key != rejected
+}
+
+type staticCredentials string
+
+// StaticCredentials returns a provider of a fixed API key.
+func StaticCredentials(key string) CredentialsProvider {
+	return staticCredentials(key)
+}
+
+func (s staticCredentials) APIKey(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+type envCredentials string
+
+// EnvCredentials returns a provider reading the API key from the
+// environment variable name, AMP360_API_KEY if empty, on every request.
+func EnvCredentials(name string) CredentialsProvider {
+	if name == "" {
+		name = "AMP360_API_KEY"
+	}
+	return envCredentials(name)
+}
+
+func (e envCredentials) APIKey(ctx context.Context) (string, error) {
+	key := os.Getenv(string(e))
+	if key == "" {
+		return "", fmt.Errorf("%w: $%s is empty", ErrNoCredentials, string(e))
+	}
+	return key, nil
+}
+
+// FileCredentials reads the API key from a file, trimmed of surrounding
+// white space. The file is read again whenever its size or modification time
+// changes, or after the server rejected the key.
+type FileCredentials struct {
+	Path string
+
+	mu      sync.Mutex
+	key     string
+	size    int64
+	modTime time.Time
+	stale   bool
+	loaded  bool
+}
+
+// NewFileCredentials returns a provider reading the API key from path.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{Path: path}
+}
+
+func (f *FileCredentials) APIKey(ctx context.Context) (string, error) {
+	fi, err := os.Stat(f.Path)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loaded && !f.stale && fi.Size() == f.size && fi.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+	b, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrNoCredentials, f.Path)
+	}
+	f.key, f.size, f.modTime = key, fi.Size(), fi.ModTime()
+	f.loaded, f.stale = true, false
+	return key, nil
+}
+
+// Refresh makes the next APIKey read the file again.
+func (f *FileCredentials) Refresh(ctx context.Context, rejected string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stale = true
+	return nil
+}
+
+type chainCredentials []CredentialsProvider
+
+// ChainCredentials returns a provider returning the key of the first of
+// providers having one.
+func ChainCredentials(providers ...CredentialsProvider) CredentialsProvider {
+	return chainCredentials(providers)
+}
+
+func (c chainCredentials) APIKey(ctx context.Context) (string, error) {
+	var errs []error
+	for _, p := range c {
+		key, err := p.APIKey(ctx)
+		if err == nil && key != "" {
+			return key, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return "", ErrNoCredentials
+	}
+	return "", errors.Join(append([]error{ErrNoCredentials}, errs...)...)
+}
+
+// Refresh refreshes every provider of the chain that supports it.
+func (c chainCredentials) Refresh(ctx context.Context, rejected string) error {
+	var errs []error
+	for _, p := range c {
+		if r, ok := p.(CredentialsRefresher); ok {
+			if err := r.Refresh(ctx, rejected); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
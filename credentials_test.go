@@ -0,0 +1,133 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("TEST_AMP360_KEY", "env-key")
+	key, err := EnvCredentials("TEST_AMP360_KEY").APIKey(context.Background())
+	if err != nil || key != "env-key" {
+		t.Errorf("APIKey got %q, error %v", key, err)
+	}
+
+	t.Setenv("TEST_AMP360_KEY", "")
+	if _, err := EnvCredentials("TEST_AMP360_KEY").APIKey(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Error got %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFileCredentials(path)
+	if key, err := f.APIKey(ctx); err != nil || key != "first" {
+		t.Fatalf("APIKey got %q, error %v", key, err)
+	}
+
+	if err := os.WriteFile(path, []byte("second-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := f.APIKey(ctx); key != "second-key" {
+		t.Errorf("APIKey after a change got %q, want %q", key, "second-key")
+	}
+
+	// same size and, on coarse file systems, same modification time
+	if err := os.WriteFile(path, []byte("third-key!\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f.Refresh(ctx, "second-key")
+	if key, _ := f.APIKey(ctx); key != "third-key!" {
+		t.Errorf("APIKey after Refresh got %q, want %q", key, "third-key!")
+	}
+}
+
+func TestChainCredentials(t *testing.T) {
+	t.Setenv("TEST_AMP360_KEY", "")
+	chain := ChainCredentials(EnvCredentials("TEST_AMP360_KEY"), StaticCredentials("static"))
+	if key, err := chain.APIKey(context.Background()); err != nil || key != "static" {
+		t.Errorf("APIKey got %q, error %v", key, err)
+	}
+
+	chain = ChainCredentials(EnvCredentials("TEST_AMP360_KEY"), StaticCredentials(""))
+	if _, err := chain.APIKey(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Error got %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestUnauthorizedRefetchMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c.SetCredentials(NewFileCredentials(path))
+
+	var calls atomic.Int32
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"success":false,"message":"unauthorized","data":{}}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":0,"rows":[]}}`)
+	})
+
+	_, err := c.ModelsService.List(context.Background())
+	if !errors.Is(err, ErrIvalidToken) || calls.Load() != 1 {
+		t.Errorf("Error got %v after %d calls, want %v after 1", err, calls.Load(), ErrIvalidToken)
+	}
+
+	// rotated behind the back of the client, with the same size and time
+	fi, _ := os.Stat(path)
+	if err := os.WriteFile(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, fi.ModTime(), fi.ModTime())
+	calls.Store(0)
+	if _, err := c.ModelsService.List(context.Background()); err != nil {
+		t.Errorf("Error occured = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls got %d, want 2", calls.Load())
+	}
+}
+
+func TestSetAPIKeyConcurrent(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":0,"rows":[]}}`)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			c.SetAPIKey(fmt.Sprintf("key-%d", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ModelsService.List(context.Background()); err != nil {
+				t.Errorf("Error occured = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
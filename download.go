@@ -0,0 +1,51 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Download writes the file at path to w and returns the number of bytes
+// written. path is the FilePath of a file parameter, either absolute or
+// relative to the base URL. Like for every request, the API key is sent
+// only to the host of the base URL.
+func (c *Client) Download(ctx context.Context, path string, w io.Writer) (n int64, err error) {
+	ctx = withOperation(ctx, OpFilesDownload, "Client.Download")
+	if path == "" {
+		return 0, errors.New("required file path is missing")
+	}
+	rel, err := url.Parse(path)
+	if err != nil {
+		return 0, err
+	}
+	u := c.BaseURL.ResolveReference(rel)
+	ctx, span := c.startOperation(ctx, http.MethodGet, u)
+	defer func() { endSpan(span, err) }()
+
+	res, attempts, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+		return req, nil
+	})
+	traceResponse(span, res, attempts)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(res.Body)
+		apiErr := newAPIError(res, raw)
+		apiErr.Attempts = attempts
+		return 0, apiErr
+	}
+	return io.Copy(w, res.Body)
+}
@@ -0,0 +1,58 @@
+package amp360
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	c.SetAPIKey("key")
+
+	mux.HandleFunc("/files/emv.xml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if got := r.Header.Get("Authorization"); got != "key" {
+			t.Errorf("Authorization got %q, want %q", got, "key")
+		}
+		fmt.Fprint(w, "<emv/>")
+	})
+
+	buf := &bytes.Buffer{}
+	n, err := c.Download(context.Background(), "files/emv.xml", buf)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if n != 6 || buf.String() != "<emv/>" {
+		t.Errorf("Download got %d bytes %q", n, buf)
+	}
+
+	_, err = c.Download(context.Background(), "files/missing.xml", buf)
+	if !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Error got %v, want %v", err, ErrEntityNotFound)
+	}
+}
+
+func TestDownloadOtherHost(t *testing.T) {
+	c, _, _, teardown := setup()
+	defer teardown()
+	c.SetAPIKey("key")
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("API key sent to another host: %q", got)
+		}
+		fmt.Fprint(w, "data")
+	}))
+	defer other.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := c.Download(context.Background(), other.URL+"/emv.xml", buf); err != nil || buf.String() != "data" {
+		t.Errorf("Download got %q, error %v", buf, err)
+	}
+}
@@ -0,0 +1,197 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// ParamDrift is a terminal parameter whose value differs from its template.
+type ParamDrift struct {
+	TerminalID   int    `json:"terminalId"`
+	SerialNumber string `json:"serialNumber"`
+	Category     string `json:"category"`
+	Tag          string `json:"tag"`
+	Name         string `json:"name"`
+	Expected     string `json:"expected"`
+	Actual       string `json:"actual"`
+	Editable     bool   `json:"editable"`
+}
+
+// DriftError reports a terminal whose parameters could not be compared.
+type DriftError struct {
+	TerminalID   int    `json:"terminalId"`
+	SerialNumber string `json:"serialNumber"`
+	Error        string `json:"error"`
+}
+
+// DriftReport lists the parameters of the terminals of a template that
+// drifted from it, sorted by category, terminal and tag.
+type DriftReport struct {
+	TemplateID int          `json:"templateId"`
+	Terminals  int          `json:"terminals"` // terminals compared
+	Drifted    int          `json:"drifted"`   // terminals with drift
+	Drifts     []ParamDrift `json:"drifts"`
+	Errors     []DriftError `json:"errors,omitempty"`
+}
+
+// Categories returns the sorted names of the categories with drift.
+func (r *DriftReport) Categories() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, d := range r.Drifts {
+		if !seen[d.Category] {
+			seen[d.Category] = true
+			names = append(names, d.Category)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByCategory groups the drifts by category name.
+func (r *DriftReport) ByCategory() map[string][]ParamDrift {
+	m := map[string][]ParamDrift{}
+	for _, d := range r.Drifts {
+		m[d.Category] = append(m[d.Category], d)
+	}
+	return m
+}
+
+// DriftOpt controls which terminals and parameters are compared.
+type DriftOpt struct {
+	// Terminals narrows the terminals listed. Terminals not assigned to the
+	// template are skipped in any case.
+	Terminals *TerminalsOpt
+	// Hidden includes the parameters not visible on terminals.
+	Hidden bool
+	// Editable includes the parameters editable on terminals, which are
+	// usually set per terminal.
+	Editable bool
+	// Workers is the number of terminals compared concurrently, 4 if zero.
+	Workers int
+}
+
+// DiffParams returns the parameters of terminal whose value differs from
+// the value of template, or from its default value when the template sets
+// none. A terminal parameter without a value takes the template's, so it
+// never drifts. Parameters missing from either side are ignored.
+func DiffParams(template *TemplateParams, terminal *TerminalParams, opt *DriftOpt) []ParamDrift {
+	if opt == nil {
+		opt = &DriftOpt{}
+	}
+	values := make(map[string]string, len(terminal.Rows))
+	for _, p := range terminal.Rows {
+		values[p.Tag] = p.Value
+	}
+
+	var drifts []ParamDrift
+	for _, p := range template.Rows {
+		if p.VisibleOnTerminal == 0 && !opt.Hidden {
+			continue
+		}
+		editable := p.EditableOnTerminal != 0
+		if editable && !opt.Editable {
+			continue
+		}
+		actual, ok := values[p.Tag]
+		if !ok {
+			continue
+		}
+		expected := effectiveValue(p.Value, p.DefaultValue)
+		if actual == "" {
+			actual = expected
+		}
+		if actual != expected {
+			drifts = append(drifts, ParamDrift{
+				Category: p.CategoryName,
+				Tag:      p.Tag,
+				Name:     p.Name,
+				Expected: expected,
+				Actual:   actual,
+				Editable: editable,
+			})
+		}
+	}
+	return drifts
+}
+
+func effectiveValue(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// Drift compares the parameters of every terminal assigned to template id
+// with the template. Terminals whose parameters can't be fetched are
+// reported in the Errors of the report.
+func (c *TemplatesService) Drift(ctx context.Context, id int, opt *DriftOpt) (*DriftReport, error) {
+	if id == 0 {
+		return nil, errors.New("required templateID is missing")
+	}
+	if opt == nil {
+		opt = &DriftOpt{}
+	}
+	template, err := c.Params(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type target struct {
+		id     int
+		serial string
+	}
+	var terminals []target
+	it := c.client.TerminalsService.All(ctx, opt.Terminals)
+	for it.Next() {
+		if t := it.Value(); t.AppTemplateID == id {
+			terminals = append(terminals, target{id: t.ID, serial: t.SerialNumber})
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		report = &DriftReport{TemplateID: id, Drifts: []ParamDrift{}}
+	)
+	pool.Run(ctx, opt.Workers, terminals, func(t target) {
+		tp, err := c.client.TerminalsService.Params(ctx, t.id, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			report.Errors = append(report.Errors, DriftError{TerminalID: t.id, SerialNumber: t.serial, Error: err.Error()})
+			return
+		}
+		report.Terminals++
+		drifts := DiffParams(template, tp, opt)
+		if len(drifts) > 0 {
+			report.Drifted++
+		}
+		for _, d := range drifts {
+			d.TerminalID, d.SerialNumber = t.id, t.serial
+			report.Drifts = append(report.Drifts, d)
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(report.Drifts, func(i, j int) bool {
+		a, b := report.Drifts[i], report.Drifts[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.TerminalID != b.TerminalID {
+			return a.TerminalID < b.TerminalID
+		}
+		return a.Tag < b.Tag
+	})
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].TerminalID < report.Errors[j].TerminalID })
+	return report, nil
+}
@@ -0,0 +1,79 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDiffParams(t *testing.T) {
+	template := &TemplateParams{Rows: []Param{
+		{Tag: "HOST", CategoryName: "COMMS", VisibleOnTerminal: 1, Value: "10.0.0.1"},
+		{Tag: "PORT", CategoryName: "COMMS", VisibleOnTerminal: 1, DefaultValue: "443"},
+		{Tag: "TID", CategoryName: "ACQ", VisibleOnTerminal: 1, EditableOnTerminal: 1},
+		{Tag: "KEY", CategoryName: "SEC", Value: "A"},
+		{Tag: "GONE", CategoryName: "COMMS", VisibleOnTerminal: 1, Value: "x"},
+		{Tag: "APN", CategoryName: "COMMS", VisibleOnTerminal: 1, Value: "internet"},
+	}}
+	terminal := &TerminalParams{Rows: []Parameter{
+		{Tag: "HOST", Value: "10.0.0.2"},
+		{Tag: "PORT", DefaultValue: "443"},
+		{Tag: "TID", Value: "12345678"},
+		{Tag: "KEY", Value: "B"},
+		{Tag: "APN", DefaultValue: "wap"},
+	}}
+
+	drifts := DiffParams(template, terminal, nil)
+	if len(drifts) != 1 || drifts[0].Tag != "HOST" || drifts[0].Expected != "10.0.0.1" || drifts[0].Actual != "10.0.0.2" {
+		t.Errorf("drifts got %+v", drifts)
+	}
+
+	drifts = DiffParams(template, terminal, &DriftOpt{Hidden: true, Editable: true})
+	if len(drifts) != 3 {
+		t.Fatalf("drifts got %+v, want 3", drifts)
+	}
+	if !drifts[1].Editable || drifts[2].Category != "SEC" {
+		t.Errorf("drifts got %+v", drifts)
+	}
+}
+
+func TestTemplatesDriftMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/templates/params/814", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"categories":[],"count":2,"rows":[{"tag":"HOST","categoryName":"COMMS","visibleOnTerminal":1,"value":"10.0.0.1"},{"tag":"PORT","categoryName":"COMMS","visibleOnTerminal":1,"defaultValue":"443"}]}}`)
+	})
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":3,"rows":[{"id":1,"serialNumber":"S1","AppTemplateId":814},{"id":2,"serialNumber":"S2","AppTemplateId":814},{"id":3,"serialNumber":"S3","AppTemplateId":900}]}}`)
+	})
+	mux.HandleFunc("/terminals/params/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"categories":[],"count":2,"rows":[{"tag":"HOST","value":"10.0.0.1"},{"tag":"PORT","value":"8443"}]}}`)
+	})
+	mux.HandleFunc("/terminals/params/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"success":false,"message":"broken","data":{}}`)
+	})
+	mux.HandleFunc("/terminals/params/3", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("terminal of another template was compared")
+	})
+
+	r, err := c.TemplatesService.Drift(context.Background(), 814, nil)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if r.Terminals != 1 || r.Drifted != 1 || len(r.Drifts) != 1 {
+		t.Fatalf("report got %+v", r)
+	}
+	if d := r.Drifts[0]; d.TerminalID != 1 || d.SerialNumber != "S1" || d.Tag != "PORT" || d.Expected != "443" || d.Actual != "8443" {
+		t.Errorf("drift got %+v", d)
+	}
+	if len(r.Errors) != 1 || r.Errors[0].TerminalID != 2 {
+		t.Errorf("errors got %+v", r.Errors)
+	}
+	if got := r.ByCategory()["COMMS"]; len(got) != 1 {
+		t.Errorf("ByCategory got %+v", r.ByCategory())
+	}
+}
@@ -1,17 +1,112 @@
-package amp360
-
-import "errors"
-
-var (
-	ErrSuccess        error = errors.New("success")
-	ErrCreated        error = errors.New("created")
-	ErrIncorrect      error = errors.New("incorrect properties")
-	ErrEntityNotFound error = errors.New("entity not found")
-	ErrAcqNotExist    error = errors.New("acquirer does not exists or incorrect properties")
-	ErrIvalidToken    error = errors.New("authentication token validation error")
-	ErrNoPermission   error = errors.New("do not have permission")
-	ErrConflict       error = errors.New("entity with serial number exists")
-	ErrUnknown        error = errors.New("unknown error")
-	ErrUnauthorized   error = errors.New("api: unauthorized access")
-	ErrNotFound       error = errors.New("api: not found")
-)
+package amp360
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrSuccess        error = errors.New("success")
+	ErrCreated        error = errors.New("created")
+	ErrIncorrect      error = errors.New("incorrect properties")
+	ErrEntityNotFound error = errors.New("entity not found")
+	ErrAcqNotExist    error = errors.New("acquirer does not exists or incorrect properties")
+	ErrIvalidToken    error = errors.New("authentication token validation error")
+	ErrNoPermission   error = errors.New("do not have permission")
+	ErrConflict       error = errors.New("entity with serial number exists")
+	ErrUnknown        error = errors.New("unknown error")
+	ErrUnauthorized   error = errors.New("api: unauthorized access")
+	ErrNotFound       error = errors.New("api: not found")
+)
+
+// APIError is returned for every unsuccessful AMP360 response. It keeps the
+// HTTP and server details of the failure and unwraps to one of the sentinel
+// errors above, so errors.Is(err, ErrConflict) and friends keep working.
+type APIError struct {
+	StatusCode int             // HTTP status code of the response
+	Method     string          // HTTP method of the request
+	Path       string          // request URL path, relative to the host
+	Message    string          // "message" field returned by the server
+	Data       json.RawMessage // "data" payload returned by the server, if any
+	Body       []byte          // raw response body
+	RequestID  string          // request ID reported by the server, if any
+	Attempts   int             // number of attempts made, including retries
+	Err        error           // matching sentinel error, may be nil
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("api err: %s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the legacy bulk endpoint sentinels as well, so callers that used
+// to compare against ErrUnauthorized or ErrNotFound are not broken.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Amz-Apigw-Id"}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		Body:       body,
+	}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.Path = res.Request.URL.Path
+	}
+	for _, h := range requestIDHeaders {
+		if id := res.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+
+	resp := struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}{}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&resp); err == nil {
+		e.Message = resp.Message
+		e.Data = resp.Data
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		e.Err = ErrIncorrect
+	case http.StatusUnauthorized:
+		e.Err = ErrIvalidToken
+	case http.StatusForbidden:
+		e.Err = ErrNoPermission
+	case http.StatusConflict:
+		e.Err = ErrConflict
+	case http.StatusNotFound:
+		e.Err = ErrEntityNotFound
+	case http.StatusBadGateway:
+		if strings.Contains(e.Message, "Failed to find") {
+			e.Err = ErrEntityNotFound
+		}
+	default:
+		e.Err = ErrUnknown
+	}
+	return e
+}
@@ -0,0 +1,109 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"success":false,"message":"Terminal with serial number already exists.","data":{"serialNumber":"8000044499"}}`)
+	})
+
+	ct := CreatedTerminal{}
+	err := c.TerminalsService.Create(context.Background(), &NewTerminal{SerialNumber: "8000044499"}, &ct)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Error got %v, want %v", err, ErrConflict)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error got %T, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("StatusCode got %v, want %v", apiErr.StatusCode, http.StatusConflict)
+	}
+	if apiErr.Method != http.MethodPost {
+		t.Errorf("Method got %v, want %v", apiErr.Method, http.MethodPost)
+	}
+	if apiErr.Path != baseURLPath+"/terminals" {
+		t.Errorf("Path got %v, want %v", apiErr.Path, baseURLPath+"/terminals")
+	}
+	if apiErr.Message != "Terminal with serial number already exists." {
+		t.Errorf("Message got %q", apiErr.Message)
+	}
+	if string(apiErr.Data) != `{"serialNumber":"8000044499"}` {
+		t.Errorf("Data got %s", apiErr.Data)
+	}
+	if apiErr.RequestID != "req-1" {
+		t.Errorf("RequestID got %v, want %v", apiErr.RequestID, "req-1")
+	}
+}
+
+func TestAPIErrorStatusMapping(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		want    error
+	}{
+		{http.StatusBadRequest, "bad request", ErrIncorrect},
+		{http.StatusUnauthorized, "", ErrIvalidToken},
+		{http.StatusUnauthorized, "", ErrUnauthorized},
+		{http.StatusForbidden, "", ErrNoPermission},
+		{http.StatusNotFound, "", ErrEntityNotFound},
+		{http.StatusNotFound, "", ErrNotFound},
+		{http.StatusBadGateway, "Failed to find terminal", ErrEntityNotFound},
+		{http.StatusInternalServerError, "", ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		c, mux, _, teardown := setup()
+		mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			fmt.Fprintf(w, `{"success":false,"message":%q,"data":{}}`, tt.message)
+		})
+
+		err := c.ModelsService.GetList(context.Background(), &ModelsList{})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("status %d: error got %v, want %v", tt.status, err, tt.want)
+		}
+		teardown()
+	}
+}
+
+func TestAPIErrorBulkMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals/params/bulk/404", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success":false,"message":"Failed to find terminal."}`)
+	})
+	mux.HandleFunc("/terminals/params/bulk/200", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":false,"message":"Nothing updated.","failed":[],"updated":[]}`)
+	})
+
+	updated := []string{}
+	failed := []string{}
+	err := c.TerminalsService.UpdateParams(context.Background(), 404, map[string]string{"a": "b"}, nil, &updated, &failed)
+	if !errors.Is(err, ErrEntityNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Error got %v, want %v", err, ErrEntityNotFound)
+	}
+
+	err = c.TerminalsService.UpdateParams(context.Background(), 200, map[string]string{"a": "b"}, nil, &updated, &failed)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error got %T, want *APIError", err)
+	}
+	if apiErr.Message != "Nothing updated." {
+		t.Errorf("Message got %q, want %q", apiErr.Message, "Nothing updated.")
+	}
+}
@@ -0,0 +1,4 @@
+package amp360
+
+// FirmwareService reports the firmware run by the terminals of the client.
+type FirmwareService service
@@ -0,0 +1,160 @@
+package amp360
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// ComplianceTerminal is a terminal of a FirmwareGroup.
+type ComplianceTerminal struct {
+	ID           int    `json:"id"`
+	SerialNumber string `json:"serialNumber"`
+	Queued       bool   `json:"queued"` // a firmware upgrade is queued
+}
+
+// FirmwareGroup is the terminals of a model running a firmware.
+type FirmwareGroup struct {
+	ModelID    string               `json:"modelId"`
+	Model      string               `json:"model"`
+	FirmwareID string               `json:"firmwareId"`
+	Firmware   string               `json:"firmware"`
+	Version    string               `json:"version"`
+	Latest     bool                 `json:"latest"` // the firmware is the latest of the model
+	Terminals  []ComplianceTerminal `json:"terminals"`
+}
+
+// ComplianceError reports a terminal whose details could not be fetched.
+type ComplianceError struct {
+	TerminalID   int    `json:"terminalId"`
+	SerialNumber string `json:"serialNumber"`
+	Error        string `json:"error"`
+}
+
+// ComplianceReport groups the terminals of the fleet by model and firmware,
+// sorted by model name and version.
+type ComplianceReport struct {
+	Terminals int               `json:"terminals"` // terminals checked
+	Outdated  int               `json:"outdated"`  // terminals not on the latest firmware
+	Groups    []FirmwareGroup   `json:"groups"`
+	Errors    []ComplianceError `json:"errors,omitempty"`
+}
+
+// OutdatedGroups returns the groups not on the latest firmware of their
+// model.
+func (r *ComplianceReport) OutdatedGroups() []FirmwareGroup {
+	var groups []FirmwareGroup
+	for _, g := range r.Groups {
+		if !g.Latest {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// ComplianceOpt controls which terminals are checked.
+type ComplianceOpt struct {
+	// Terminals narrows the terminals listed.
+	Terminals *TerminalsOpt
+	// Workers is the number of terminals checked concurrently, 4 if zero.
+	Workers int
+}
+
+// Compliance fetches the details of every terminal matching opt and groups
+// the terminals by model and firmware. Terminals whose details can't be
+// fetched are reported in the Errors of the report.
+func (c *FirmwareService) Compliance(ctx context.Context, opt *ComplianceOpt) (*ComplianceReport, error) {
+	if opt == nil {
+		opt = &ComplianceOpt{}
+	}
+
+	var terminals []ComplianceTerminal
+	it := c.client.TerminalsService.All(ctx, opt.Terminals)
+	for it.Next() {
+		t := it.Value()
+		terminals = append(terminals, ComplianceTerminal{ID: t.ID, SerialNumber: t.SerialNumber})
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	type groupKey struct{ model, firmware string }
+	var (
+		mu     sync.Mutex
+		report = &ComplianceReport{Groups: []FirmwareGroup{}}
+		groups = map[groupKey]*FirmwareGroup{}
+	)
+	pool.Run(ctx, opt.Workers, terminals, func(t ComplianceTerminal) {
+		d, err := c.client.TerminalsService.Details(ctx, &TerminalsOpt{ID: t.ID})
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			report.Errors = append(report.Errors, ComplianceError{TerminalID: t.ID, SerialNumber: t.SerialNumber, Error: err.Error()})
+			return
+		}
+		dt := &d.Terminal
+		k := groupKey{dt.TerminalModelID, dt.FirmwareID}
+		g, ok := groups[k]
+		if !ok {
+			g = &FirmwareGroup{
+				ModelID:    dt.TerminalModelID,
+				Model:      dt.TerminalModel.Name,
+				FirmwareID: dt.FirmwareID,
+				Firmware:   dt.Firmware.Name,
+				Version:    dt.Firmware.Version,
+				Latest:     dt.Firmware.IsLatest != 0,
+			}
+			groups[k] = g
+		}
+		t.Queued = dt.QueueFirmware != 0
+		g.Terminals = append(g.Terminals, t)
+		report.Terminals++
+		if !g.Latest {
+			report.Outdated++
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, g := range groups {
+		sort.Slice(g.Terminals, func(i, j int) bool { return g.Terminals[i].ID < g.Terminals[j].ID })
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		if c := compareVersions(a.Version, b.Version); c != 0 {
+			return c < 0
+		}
+		return a.FirmwareID < b.FirmwareID
+	})
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].TerminalID < report.Errors[j].TerminalID })
+	return report, nil
+}
+
+// compareVersions compares the dotted versions a and b part by part,
+// numerically when both parts are numbers, so "1.9" comes before "1.10".
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		x, errX := strconv.Atoi(pa[i])
+		y, errY := strconv.Atoi(pb[i])
+		switch {
+		case errX == nil && errY == nil && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		case (errX != nil || errY != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return len(pa) - len(pb)
+}
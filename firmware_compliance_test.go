@@ -0,0 +1,76 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFirmwareComplianceMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"count":4,"rows":[
+			{"id":25,"serialNumber":"S25"},{"id":26,"serialNumber":"S26"},
+			{"id":27,"serialNumber":"S27"},{"id":28,"serialNumber":"S28"}]}}`)
+	})
+	details := map[string]string{
+		"25": `{"id":25,"FirmwareId":"f2","TerminalModelId":"m1","Firmware":{"id":"f2","name":"AMP8000-2AA","version":"03.02.39","isLatest":1},"TerminalModel":{"id":"m1","name":"AMP8000"}}`,
+		"26": `{"id":26,"queueFirmware":1,"FirmwareId":"f1","TerminalModelId":"m1","Firmware":{"id":"f1","name":"AMP8000-2AA","version":"03.02.38","isLatest":0},"TerminalModel":{"id":"m1","name":"AMP8000"}}`,
+		"27": `{"id":27,"FirmwareId":"f1","TerminalModelId":"m1","Firmware":{"id":"f1","name":"AMP8000-2AA","version":"03.02.38","isLatest":0},"TerminalModel":{"id":"m1","name":"AMP8000"}}`,
+	}
+	mux.HandleFunc("/terminals/details", func(w http.ResponseWriter, r *http.Request) {
+		d, ok := details[r.URL.Query().Get("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"message":"Terminal not found."}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success":true,"data":{"templateDetails":[],"terminal":%s}}`, d)
+	})
+
+	r, err := c.FirmwareService.Compliance(context.Background(), &ComplianceOpt{Workers: 2})
+	if err != nil {
+		t.Fatalf("Compliance returned error: %v", err)
+	}
+	if r.Terminals != 3 || r.Outdated != 2 || len(r.Groups) != 2 {
+		t.Fatalf("Compliance = %+v", r)
+	}
+	old := r.Groups[0]
+	if old.Version != "03.02.38" || old.Latest || old.Model != "AMP8000" || len(old.Terminals) != 2 {
+		t.Errorf("outdated group = %+v", old)
+	}
+	if old.Terminals[0].ID != 26 || !old.Terminals[0].Queued || old.Terminals[1].Queued {
+		t.Errorf("outdated terminals = %+v", old.Terminals)
+	}
+	if g := r.Groups[1]; !g.Latest || len(g.Terminals) != 1 || g.Terminals[0].SerialNumber != "S25" {
+		t.Errorf("latest group = %+v", g)
+	}
+	if len(r.OutdatedGroups()) != 1 {
+		t.Errorf("OutdatedGroups = %+v", r.OutdatedGroups())
+	}
+	if len(r.Errors) != 1 || r.Errors[0].TerminalID != 28 {
+		t.Errorf("Errors = %+v", r.Errors)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.9", "1.10", -1},
+		{"03.02.39", "03.02.38", 1},
+		{"03.02.38", "3.2.38", 0},
+		{"1.2", "1.2.1", -1},
+		{"v02.02.022", "v02.02.022", 0},
+		{"1.0-beta", "1.0-rc", -1},
+	} {
+		got := compareVersions(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("compareVersions(%q, %q) got %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package pool runs work on a bounded number of goroutines.
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWorkers is the number of goroutines of Run when none is given.
+const DefaultWorkers = 4
+
+// Run calls fn for the items, in order, on up to workers goroutines,
+// DefaultWorkers if workers is not positive. It stops handing out items once
+// ctx is done and returns after the calls in flight, with the number of
+// items handed out. fn must do its own locking.
+func Run[T any](ctx context.Context, workers int, items []T, fn func(T)) int {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	jobs := make(chan T)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				fn(item)
+			}
+		}()
+	}
+
+	n := 0
+feed:
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- item:
+			n++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return n
+}
@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		running, peak int32
+		mu            sync.Mutex
+		sum           int
+	)
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	n := Run(context.Background(), 3, items, func(i int) {
+		r := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if r <= p || atomic.CompareAndSwapInt32(&peak, p, r) {
+				break
+			}
+		}
+		mu.Lock()
+		sum += i
+		mu.Unlock()
+	})
+	if n != len(items) || sum != 55 {
+		t.Errorf("Run handed out %d items summing to %d, want %d and 55", n, sum, len(items))
+	}
+	if peak > 3 {
+		t.Errorf("%d calls ran concurrently, want at most 3", peak)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int32
+	n := Run(ctx, 1, []int{1, 2, 3}, func(int) { atomic.AddInt32(&calls, 1) })
+	if n != 0 || calls != 0 {
+		t.Errorf("Run handed out %d items and made %d calls, want none", n, calls)
+	}
+}
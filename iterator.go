@@ -0,0 +1,223 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const defaultPageSize = 100
+
+// ErrTooManyItems is returned by Collect when the iterator holds more items
+// than allowed.
+var ErrTooManyItems = errors.New("iterator: too many items")
+
+// PageError reports the page whose fetch failed.
+type PageError struct {
+	Page int
+	Err  error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("page %d: %v", e.Page, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+type pageFunc[T any] func(ctx context.Context, page, size int) (rows []T, count int, err error)
+
+type pageResult[T any] struct {
+	rows  []T
+	count int
+	err   error
+}
+
+// Iterator walks the rows of a paginated list, fetching pages lazily. It
+// stops once the number of rows reported by the server has been read.
+//
+//	it := c.TerminalsService.All(ctx, nil)
+//	for it.Next() {
+//		t := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	ctx   context.Context
+	fetch pageFunc[T]
+	size  int
+
+	page     int // next page to fetch
+	seen     int // rows up to the end of the last page fetched
+	count    int // rows reported by the server, -1 until known
+	buf      []T
+	cur      T
+	err      error
+	last     bool // no more pages to fetch
+	prefetch bool
+	pending  chan pageResult[T]
+}
+
+func newIterator[T any](ctx context.Context, page, size int, fetch pageFunc[T]) *Iterator[T] {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	return &Iterator[T]{
+		ctx:   ctx,
+		fetch: fetch,
+		size:  size,
+		page:  page,
+		seen:  (page - 1) * size, // the rows of the pages skipped
+		count: -1,
+	}
+}
+
+// Prefetch makes the iterator fetch the next page concurrently while the
+// current one is being consumed. It must be called before the first Next.
+func (it *Iterator[T]) Prefetch() *Iterator[T] {
+	it.prefetch = true
+	return it
+}
+
+// Next advances to the next row, fetching a new page if needed. It returns
+// false when all rows were read or a page failed; see Err.
+func (it *Iterator[T]) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || it.last {
+			return false
+		}
+		it.nextPage()
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Value returns the current row.
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+// Err returns the first error met, as a *PageError.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Count returns the number of rows reported by the server, or -1 before the
+// first page is fetched.
+func (it *Iterator[T]) Count() int {
+	return it.count
+}
+
+func (it *Iterator[T]) nextPage() {
+	page := it.page
+	var res pageResult[T]
+	if it.pending != nil {
+		res = <-it.pending
+		it.pending = nil
+	} else {
+		res = it.get(page)
+	}
+	it.page++
+
+	if res.err != nil {
+		it.err = &PageError{Page: page, Err: res.err}
+		return
+	}
+	it.count = res.count
+	it.seen += len(res.rows)
+	it.buf = res.rows
+	if len(res.rows) == 0 || it.seen >= it.count {
+		it.last = true
+		return
+	}
+
+	if it.prefetch {
+		it.pending = make(chan pageResult[T], 1)
+		go func(page int, ch chan<- pageResult[T]) {
+			ch <- it.get(page)
+		}(it.page, it.pending)
+	}
+}
+
+func (it *Iterator[T]) get(page int) pageResult[T] {
+	rows, count, err := it.fetch(it.ctx, page, it.size)
+	return pageResult[T]{rows: rows, count: count, err: err}
+}
+
+// Collect reads the rows of it into a slice. If max is positive and the
+// iterator holds more than max rows, the first max rows are returned along
+// with ErrTooManyItems.
+func Collect[T any](it *Iterator[T], max int) ([]T, error) {
+	var rows []T
+	for it.Next() {
+		if max > 0 && len(rows) == max {
+			return rows, ErrTooManyItems
+		}
+		rows = append(rows, it.Value())
+	}
+	return rows, it.Err()
+}
+
+// pagedOpt is the options type O of a paged list, giving access to its page
+// and size fields.
+type pagedOpt[O any] interface {
+	*O
+	paging() (page, size *int)
+}
+
+// pagedAll returns an iterator over the rows fetched by fetch, starting at
+// the page and size of opt. fetch is called with a copy of opt for each page.
+func pagedAll[T, O any, P pagedOpt[O]](ctx context.Context, opt P, fetch func(ctx context.Context, opt P) ([]T, int, error)) *Iterator[T] {
+	var o O
+	if opt != nil {
+		o = *opt
+	}
+	page, size := P(&o).paging()
+	return newIterator(ctx, *page, *size, func(ctx context.Context, page, size int) ([]T, int, error) {
+		q := o
+		p, s := P(&q).paging()
+		*p, *s = page, size
+		return fetch(ctx, &q)
+	})
+}
+
+func (o *TerminalsOpt) paging() (page, size *int) { return &o.Page, &o.Size }
+func (o *TemplatesOpt) paging() (page, size *int) { return &o.Page, &o.Size }
+func (o *CompaniesOpt) paging() (page, size *int) { return &o.Page, &o.Size }
+
+// All returns an iterator over the terminals matching opt.
+func (c *TerminalsService) All(ctx context.Context, opt *TerminalsOpt) *Iterator[Terminal] {
+	return pagedAll(ctx, opt, func(ctx context.Context, opt *TerminalsOpt) ([]Terminal, int, error) {
+		l, err := c.List(ctx, opt)
+		if err != nil {
+			return nil, 0, err
+		}
+		return l.Rows, l.Count, nil
+	})
+}
+
+// All returns an iterator over the templates of the client.
+func (c *TemplatesService) All(ctx context.Context, opt *TemplatesOpt) *Iterator[Template] {
+	return pagedAll(ctx, opt, func(ctx context.Context, opt *TemplatesOpt) ([]Template, int, error) {
+		l, err := c.List(ctx, opt)
+		if err != nil {
+			return nil, 0, err
+		}
+		return l.Rows, l.Count, nil
+	})
+}
+
+// All returns an iterator over the sub-companies of the client.
+func (c *CompaniesService) All(ctx context.Context, opt *CompaniesOpt) *Iterator[Company] {
+	return pagedAll(ctx, opt, func(ctx context.Context, opt *CompaniesOpt) ([]Company, int, error) {
+		l, err := c.List(ctx, opt)
+		if err != nil {
+			return nil, 0, err
+		}
+		return l.Rows, l.Count, nil
+	})
+}
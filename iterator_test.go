@@ -0,0 +1,134 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func terminalsPageHandler(t *testing.T, total int, failPage int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if page == failPage {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"success":false,"message":"unavailable"}`)
+			return
+		}
+		rows := ""
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			if rows != "" {
+				rows += ","
+			}
+			rows += fmt.Sprintf(`{"id":%d,"serialNumber":"SN%d"}`, i+1, i+1)
+		}
+		fmt.Fprintf(w, `{"success":true,"message":"ok","data":{"count":%d,"rows":[%s]}}`, total, rows)
+	}
+}
+
+func TestTerminalsAllMock(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		c, mux, _, teardown := setup()
+		mux.HandleFunc("/terminals", terminalsPageHandler(t, 7, 0))
+
+		it := c.TerminalsService.All(context.Background(), &TerminalsOpt{Size: 3})
+		if prefetch {
+			it.Prefetch()
+		}
+		ids := []int{}
+		for it.Next() {
+			ids = append(ids, it.Value().ID)
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("Error occured = %v", err)
+		}
+		if len(ids) != 7 || ids[6] != 7 {
+			t.Errorf("prefetch %v: ids got %v, want 1..7", prefetch, ids)
+		}
+		if it.Count() != 7 {
+			t.Errorf("Count got %v, want %v", it.Count(), 7)
+		}
+		teardown()
+	}
+}
+
+func TestTerminalsAllFromPageMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	requests := 0
+	pages := terminalsPageHandler(t, 7, 0)
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		pages(w, r)
+	})
+
+	rows, err := Collect(c.TerminalsService.All(context.Background(), &TerminalsOpt{Page: 2, Size: 3}), 0)
+	if err != nil {
+		t.Errorf("Error occured = %v", err)
+	}
+	if len(rows) != 4 || rows[0].ID != 4 || rows[3].ID != 7 {
+		t.Errorf("rows got %d, want 4..7", len(rows))
+	}
+	// pages 2 and 3, without asking for an empty page 4
+	if requests != 2 {
+		t.Errorf("requests got %v, want %v", requests, 2)
+	}
+}
+
+func TestTerminalsAllPageErrorMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/terminals", terminalsPageHandler(t, 7, 2))
+
+	rows, err := Collect(c.TerminalsService.All(context.Background(), &TerminalsOpt{Size: 3}), 0)
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) || pageErr.Page != 2 {
+		t.Fatalf("Error got %v, want page 2 error", err)
+	}
+	if !errors.Is(err, ErrUnknown) {
+		t.Errorf("Error got %v, want %v", err, ErrUnknown)
+	}
+	if len(rows) != 3 {
+		t.Errorf("rows got %v, want %v", len(rows), 3)
+	}
+}
+
+func TestCollectMaxMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/terminals", terminalsPageHandler(t, 10, 0))
+
+	rows, err := Collect(c.TerminalsService.All(context.Background(), &TerminalsOpt{Size: 4}), 5)
+	if !errors.Is(err, ErrTooManyItems) {
+		t.Errorf("Error got %v, want %v", err, ErrTooManyItems)
+	}
+	if len(rows) != 5 {
+		t.Errorf("rows got %v, want %v", len(rows), 5)
+	}
+
+	rows, err = Collect(c.TerminalsService.All(context.Background(), &TerminalsOpt{Size: 4}), 10)
+	if err != nil || len(rows) != 10 {
+		t.Errorf("Collect got %v rows, error %v, want 10 rows", len(rows), err)
+	}
+}
+
+func TestCompaniesAllMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/client/children", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":2,"rows":[]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":2,"rows":[{"id":"test1"},{"id":"test2"}]}}`)
+	})
+
+	rows, err := Collect(c.CompaniesService.All(context.Background(), nil), 0)
+	if err != nil || len(rows) != 2 {
+		t.Errorf("Collect got %v rows, error %v, want 2 rows", len(rows), err)
+	}
+}
@@ -0,0 +1,104 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NopLogger discards everything logged to it.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Warnf(format string, args ...interface{})  {}
+func (nopLogger) Errorf(format string, args ...interface{}) {}
+
+// NewSlogLogger returns a Logger writing to l.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) logf(level slog.Level, format string, args ...interface{}) {
+	ctx := context.Background()
+	if s.l.Enabled(ctx, level) {
+		s.l.Log(ctx, level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (s slogLogger) Debugf(format string, args ...interface{}) {
+	s.logf(slog.LevelDebug, format, args...)
+}
+func (s slogLogger) Infof(format string, args ...interface{}) {
+	s.logf(slog.LevelInfo, format, args...)
+}
+func (s slogLogger) Warnf(format string, args ...interface{}) {
+	s.logf(slog.LevelWarn, format, args...)
+}
+func (s slogLogger) Errorf(format string, args ...interface{}) {
+	s.logf(slog.LevelError, format, args...)
+}
+
+// NewStdLogger returns a Logger writing to l, prefixing every line with its
+// level.
+func NewStdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (s stdLogger) Debugf(format string, args ...interface{}) { s.l.Printf("DEBUG "+format, args...) }
+func (s stdLogger) Infof(format string, args ...interface{})  { s.l.Printf("INFO "+format, args...) }
+func (s stdLogger) Warnf(format string, args ...interface{})  { s.l.Printf("WARN "+format, args...) }
+func (s stdLogger) Errorf(format string, args ...interface{}) { s.l.Printf("ERROR "+format, args...) }
+
+// SetLogger sets the logger of the client. A nil logger disables logging.
+func (c *Client) SetLogger(l Logger) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.logger = l
+}
+
+// Logger returns the logger of the client.
+func (c *Client) Logger() Logger {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	if c.logger == nil {
+		return NopLogger
+	}
+	return c.logger
+}
+
+// redactHeaders formats h for logging with credentials hidden.
+func redactHeaders(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		v := strings.Join(h[k], ",")
+		if http.CanonicalHeaderKey(k) == "Authorization" {
+			v = "REDACTED"
+		}
+		fmt.Fprintf(&b, "%s: %s", k, v)
+	}
+	return b.String()
+}
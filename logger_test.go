@@ -0,0 +1,120 @@
+package amp360
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *recordLogger) add(level, format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (r *recordLogger) Debugf(format string, args ...interface{}) { r.add("DEBUG", format, args...) }
+func (r *recordLogger) Infof(format string, args ...interface{})  { r.add("INFO", format, args...) }
+func (r *recordLogger) Warnf(format string, args ...interface{})  { r.add("WARN", format, args...) }
+func (r *recordLogger) Errorf(format string, args ...interface{}) { r.add("ERROR", format, args...) }
+
+func (r *recordLogger) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.lines, "\n")
+}
+
+func TestClientLoggerMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":"bad"}}`)
+	})
+
+	logger := &recordLogger{}
+	c.SetLogger(logger)
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	c.SetRetryPolicy(p)
+
+	if err := c.ModelsService.GetList(context.Background(), &ModelsList{}); err == nil {
+		t.Error("Error is nil, want decode error")
+	}
+
+	out := logger.String()
+	for _, want := range []string{
+		"DEBUG amp360: GET " + baseURLPath + "/models attempt 1",
+		"502 Bad Gateway",
+		"WARN amp360: retrying GET",
+		"ERROR amp360: decode GET",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoggingRoundTripperRedacts(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":0,"rows":[]}}`)
+	})
+
+	logger := &recordLogger{}
+	c.SetTransport(NewLoggingRoundTripper(http.DefaultTransport, logger))
+	c.SetAPIKey("secret-key")
+
+	if err := c.ModelsService.GetList(context.Background(), &ModelsList{}); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+
+	out := logger.String()
+	if strings.Contains(out, "secret-key") {
+		t.Errorf("log output leaks the API key:\n%s", out)
+	}
+	if !strings.Contains(out, "Authorization: REDACTED") {
+		t.Errorf("log output missing redacted header:\n%s", out)
+	}
+	if !strings.Contains(out, "INFO Response: | 200 OK") {
+		t.Errorf("log output missing response:\n%s", out)
+	}
+}
+
+func TestLoggerAdapters(t *testing.T) {
+	buf := &bytes.Buffer{}
+	std := NewStdLogger(log.New(buf, "", 0))
+	std.Warnf("hello %d", 1)
+	if got, want := buf.String(), "WARN hello 1\n"; got != want {
+		t.Errorf("std logger got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	sl := NewSlogLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	sl.Debugf("hidden")
+	sl.Errorf("failed %s", "call")
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, `level=ERROR msg="failed call"`) {
+		t.Errorf("slog logger got %q", got)
+	}
+
+	NopLogger.Errorf("discarded")
+}
@@ -0,0 +1,60 @@
+package amp360
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Metrics receives the measurements of the API calls. It must be safe for
+// concurrent use. See the amp360prom package for a Prometheus
+// implementation.
+type Metrics interface {
+	// ObserveCall is called once a call is over, after its last attempt.
+	ObserveCall(CallStats)
+	// ObserveBulk is called with the outcome of every successful bulk
+	// parameters update.
+	ObserveBulk(op string, updated, failed int)
+}
+
+// CallStats describes an API call.
+type CallStats struct {
+	Operation  string        // logical operation, e.g. "terminals.list"
+	Method     string        // HTTP method
+	StatusCode int           // status of the last response, zero if none
+	Attempts   int           // number of attempts made
+	Duration   time.Duration // time spent on all the attempts and the waits between them
+	Err        error         // transport or context error of the last attempt
+}
+
+// Failed reports whether the call got no response or an error status.
+func (s CallStats) Failed() bool {
+	return s.Err != nil || s.StatusCode == 0 || s.StatusCode >= 400
+}
+
+// SetMetrics makes the client report its calls to m. A nil m disables
+// reporting.
+func (c *Client) SetMetrics(m Metrics) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.metrics = m
+}
+
+// Metrics returns the metrics of the client, nil if none was set.
+func (c *Client) Metrics() Metrics {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.metrics
+}
+
+// bulkCounts returns the numbers of parameters updated and failed of the
+// bulk response raw.
+func bulkCounts(raw []byte) (updated, failed int, ok bool) {
+	var counts struct {
+		Updated []json.RawMessage `json:"updated"`
+		Failed  []json.RawMessage `json:"failed"`
+	}
+	if err := json.Unmarshal(raw, &counts); err != nil {
+		return 0, 0, false
+	}
+	return len(counts.Updated), len(counts.Failed), true
+}
@@ -0,0 +1,101 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+type fakeMetrics struct {
+	mu    sync.Mutex
+	calls []CallStats
+	bulks []string
+}
+
+func (m *fakeMetrics) ObserveCall(s CallStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, s)
+}
+
+func (m *fakeMetrics) ObserveBulk(op string, updated, failed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bulks = append(m.bulks, fmt.Sprintf("%s %d/%d", op, updated, failed))
+}
+
+func TestMetrics(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals/params/25", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"count":0,"rows":[]}}`)
+	})
+	mux.HandleFunc("/terminals/params/bulk/25", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"updated":["a","b"],"failed":["c"]}`)
+	})
+	mux.HandleFunc("/terminals/26", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success":false,"message":"Terminal not found."}`)
+	})
+	mux.HandleFunc("/custom/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	m := &fakeMetrics{}
+	c.SetMetrics(m)
+	ctx := context.Background()
+
+	if _, err := c.TerminalsService.Params(ctx, 25, nil); err != nil {
+		t.Fatalf("Params returned error: %v", err)
+	}
+	if _, err := c.TerminalsService.SetParams(ctx, 25, map[string]string{"a": "1"}, nil); err != nil {
+		t.Fatalf("SetParams returned error: %v", err)
+	}
+	if err := c.TerminalsService.Delete(ctx, 26); err == nil {
+		t.Fatal("Delete returned no error")
+	}
+	for _, rel := range []string{"custom/1", "custom/2"} {
+		req, _ := c.NewRequest(http.MethodGet, mustURL(rel), nil)
+		if rel == "custom/2" {
+			req = req.WithContext(WithOperation(ctx, "custom.named"))
+		}
+		res, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+		res.Body.Close()
+	}
+
+	want := []struct {
+		op     string
+		status int
+		failed bool
+	}{
+		{OpTerminalsParamsGet, 200, false},
+		{OpTerminalsParamsBulk, 200, false},
+		{OpTerminalsDelete, 404, true},
+		{"custom.get", 200, false},
+		{"custom.named", 404, true},
+	}
+	if len(m.calls) != len(want) {
+		t.Fatalf("observed %d calls, want %d: %+v", len(m.calls), len(want), m.calls)
+	}
+	for i, w := range want {
+		got := m.calls[i]
+		if got.Operation != w.op || got.StatusCode != w.status || got.Failed() != w.failed || got.Attempts != 1 {
+			t.Errorf("call %d = %+v, want %s %d", i, got, w.op, w.status)
+		}
+	}
+	if len(m.bulks) != 1 || m.bulks[0] != "terminals.params.bulk 2/1" {
+		t.Errorf("bulks = %v", m.bulks)
+	}
+}
+
+func mustURL(rel string) url.URL {
+	u, _ := url.Parse(rel)
+	return *u
+}
@@ -24,7 +24,15 @@ type TerminalModel struct {
 	UpdatedAt           time.Time `json:"updatedAt"`
 }
 
+// List returns the available terminal models.
+func (c *ModelsService) List(ctx context.Context) (*ModelsList, error) {
+	ctx = withOperation(ctx, OpModelsList, "ModelsService.List")
+	return fetch[ModelsList](ctx, c.client, http.MethodGet, url.URL{Path: "models"}, nil)
+}
+
+// Deprecated: use List.
 func (c *ModelsService) GetList(ctx context.Context, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpModelsList, "ModelsService.GetList")
 	path := "models"
 	url := url.URL{Path: path}
 	return c.client.processRequest(ctx, http.MethodGet, url, nil, v)
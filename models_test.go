@@ -49,3 +49,21 @@ func BenchmarkModelsGetListMock(b *testing.B) {
 		}
 	}
 }
+
+func TestModelsListMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `{"success":true,"message":"Successfully found available terminal models.","data":{"count":1,"rows":[{"name":"TEST1","id":"test1","hardwareId":"CD","maintenanceInterval":180,"jointName":"TEST1-CD","createdAt":"2021-10-30T00:55:39.000Z"}]}}`)
+	})
+
+	ml, err := c.ModelsService.List(context.Background())
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if ml.Count != 1 || ml.Rows[0].JointName != "TEST1-CD" {
+		t.Errorf("Models got %+v", ml)
+	}
+}
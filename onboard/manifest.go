@@ -0,0 +1,170 @@
+// Package onboard creates terminals in bulk from a CSV or XLSX manifest.
+//
+// A manifest has one terminal per row and a header row naming the columns.
+// The serial, name, model, client and template columns describe the
+// terminal; every other column is a parameter tag whose value is set on the
+// terminal, empty cells being ignored.
+package onboard
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Row is a terminal to onboard.
+type Row struct {
+	Line         int // line of the row in the manifest, starting at 1
+	SerialNumber string
+	Name         string
+	ModelID      string
+	ClientID     string
+	TemplateID   string
+	Params       map[string]string
+}
+
+// column aliases of the terminal fields, lower case
+var columns = map[string]string{
+	"serial":        "serial",
+	"serialnumber":  "serial",
+	"serial_number": "serial",
+	"name":          "name",
+	"model":         "model",
+	"modelid":       "model",
+	"model_id":      "model",
+	"client":        "client",
+	"clientid":      "client",
+	"client_id":     "client",
+	"template":      "template",
+	"templateid":    "template",
+	"template_id":   "template",
+}
+
+// ReadFile reads the manifest at path, a .csv or .xlsx file.
+func ReadFile(path string) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadCSV(f)
+	case ".xlsx":
+		return ReadXLSX(path)
+	}
+	return nil, fmt.Errorf("onboard: unsupported manifest type %q", filepath.Ext(path))
+}
+
+// ReadCSV reads a CSV manifest.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	var records []record
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		records = append(records, record{line: line, fields: fields})
+	}
+	return parseRecords(records)
+}
+
+// record is a row of a manifest and its line, counting blank lines and the
+// line breaks of quoted fields in a CSV, the row number in an XLSX.
+type record struct {
+	line   int
+	fields []string
+}
+
+func parseRecords(records []record) ([]Row, error) {
+	if len(records) == 0 {
+		return nil, errors.New("onboard: empty manifest")
+	}
+	header := records[0].fields
+	rows := []Row{}
+	for _, rec := range records[1:] {
+		if blank(rec.fields) {
+			continue
+		}
+		row := Row{Line: rec.line, Params: map[string]string{}}
+		for j, v := range rec.fields {
+			if j >= len(header) {
+				break
+			}
+			v = strings.TrimSpace(v)
+			col := strings.TrimSpace(header[j])
+			switch columns[strings.ToLower(col)] {
+			case "serial":
+				row.SerialNumber = v
+			case "name":
+				row.Name = v
+			case "model":
+				row.ModelID = v
+			case "client":
+				row.ClientID = v
+			case "template":
+				row.TemplateID = v
+			default:
+				if col != "" && v != "" {
+					row.Params[col] = v
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func blank(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// RowError is a validation error of a manifest row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks rows for missing fields and duplicate serial numbers. It
+// returns one error per invalid row.
+func Validate(rows []Row) []error {
+	var errs []error
+	seen := map[string]int{}
+	for _, r := range rows {
+		switch {
+		case r.SerialNumber == "":
+			errs = append(errs, &RowError{r.Line, errors.New("missing serial number")})
+		case r.ModelID == "":
+			errs = append(errs, &RowError{r.Line, errors.New("missing model")})
+		case seen[r.SerialNumber] != 0:
+			errs = append(errs, &RowError{r.Line, fmt.Errorf("serial number %s already on line %d", r.SerialNumber, seen[r.SerialNumber])})
+		default:
+			seen[r.SerialNumber] = r.Line
+		}
+	}
+	return errs
+}
@@ -0,0 +1,108 @@
+package onboard
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testManifest = `serial,name,model,template,ACQS._1.ACQINFO.TERMINALID,ACQS._1.ACQINFO.MERCHANTID
+8000000001,Shop 1,m1,814,10000001,400081203
+8000000002,Shop 2,m1,814,10000002,
+
+,,,,,
+8000000001,Shop 3,m1,814,10000003,400081203
+,Shop 4,m1,814,10000004,400081203
+`
+
+func TestReadCSV(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows got %v, want %v", len(rows), 4)
+	}
+	r := rows[0]
+	if r.Line != 2 || r.SerialNumber != "8000000001" || r.ModelID != "m1" || r.TemplateID != "814" {
+		t.Errorf("row got %+v", r)
+	}
+	if r.Params["ACQS._1.ACQINFO.TERMINALID"] != "10000001" || len(r.Params) != 2 {
+		t.Errorf("params got %v", r.Params)
+	}
+	if _, ok := rows[1].Params["ACQS._1.ACQINFO.MERCHANTID"]; ok {
+		t.Errorf("empty cell kept as parameter: %v", rows[1].Params)
+	}
+	// the blank line is counted
+	if rows[2].Line != 6 {
+		t.Errorf("line got %v, want %v", rows[2].Line, 6)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rows, _ := ReadCSV(strings.NewReader(testManifest))
+	errs := Validate(rows)
+	if len(errs) != 2 {
+		t.Fatalf("errors got %v, want 2", errs)
+	}
+	var rowErr *RowError
+	if !errors.As(errs[0], &rowErr) || rowErr.Line != 6 || !strings.Contains(rowErr.Error(), "already on line 2") {
+		t.Errorf("error got %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "line 7: missing serial number") {
+		t.Errorf("error got %v", errs[1])
+	}
+}
+
+func writeXLSX(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>serial</t></si><si><t>model</t></si><si><t>TID</t></si><si><r><t>m</t></r><r><t>1</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>` +
+			`<row r="2"><c r="A2"><v>8000000001</v></c><c r="B2" t="s"><v>3</v></c><c r="D2" t="inlineStr"><is><t>10000001</t></is></c></row>` +
+			`<row r="5"><c r="A5"><v>8000000002</v></c><c r="B5" t="s"><v>3</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.xlsx")
+	writeXLSX(t, path)
+
+	rows, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows got %v, want 2", len(rows))
+	}
+	if r := rows[0]; r.Line != 2 || r.SerialNumber != "8000000001" || r.ModelID != "m1" || r.Params["TID"] != "10000001" {
+		t.Errorf("row got %+v", r)
+	}
+	// rows 3 and 4 are empty and left out of the sheet
+	if r := rows[1]; r.Line != 5 || r.SerialNumber != "8000000002" {
+		t.Errorf("row got %+v", r)
+	}
+}
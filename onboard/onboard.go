@@ -0,0 +1,255 @@
+package onboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// ConflictPolicy tells what to do with a row whose serial number already
+// exists.
+type ConflictPolicy int
+
+const (
+	// Skip leaves the existing terminal untouched.
+	Skip ConflictPolicy = iota
+	// Update renames the existing terminal and sets the row parameters on it.
+	Update
+	// Fail reports the row as failed.
+	Fail
+)
+
+// ParsePolicy parses "skip", "update" or "fail".
+func ParsePolicy(s string) (ConflictPolicy, error) {
+	switch s {
+	case "skip":
+		return Skip, nil
+	case "update":
+		return Update, nil
+	case "fail":
+		return Fail, nil
+	}
+	return Skip, fmt.Errorf("onboard: unknown conflict policy %q", s)
+}
+
+// Status is the outcome of a row.
+type Status string
+
+const (
+	Created Status = "created"
+	Updated Status = "updated"
+	Skipped Status = "skipped"
+	Failed  Status = "failed"
+	Invalid Status = "invalid"
+)
+
+// Result is the outcome of onboarding a row.
+type Result struct {
+	Line         int    `json:"line"`
+	SerialNumber string `json:"serialNumber"`
+	Status       Status `json:"status"`
+	TerminalID   int    `json:"terminalId,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Resumed is set for rows completed by a previous run.
+	Resumed bool `json:"-"`
+}
+
+// Onboarder creates the terminals of a manifest.
+type Onboarder struct {
+	Client *amp360.Client
+	// Workers is the number of rows processed concurrently, 4 if zero.
+	Workers int
+	// OnConflict tells how to handle serial numbers that already exist.
+	OnConflict ConflictPolicy
+	// Checkpoint is the path of a file recording completed rows. Rows found
+	// in it are not processed again. Empty disables checkpointing.
+	Checkpoint string
+	// Progress, if set, is called after every row.
+	Progress func(Result)
+}
+
+// Run onboards rows and returns one result per row, ordered by line.
+// Invalid rows are reported without being sent.
+func (o *Onboarder) Run(ctx context.Context, rows []Row) ([]Result, error) {
+	done, err := loadCheckpoint(o.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	var cp *os.File
+	if o.Checkpoint != "" {
+		cp, err = os.OpenFile(o.Checkpoint, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		defer cp.Close()
+	}
+
+	invalid := map[int]string{}
+	for _, err := range Validate(rows) {
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			invalid[rowErr.Line] = rowErr.Err.Error()
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		results = make([]Result, 0, len(rows))
+		cpErr   error
+	)
+	record := func(r Result, persist bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, r)
+		if persist && cp != nil && r.Status != Failed {
+			if err := json.NewEncoder(cp).Encode(r); err != nil && cpErr == nil {
+				cpErr = err
+			}
+		}
+		if o.Progress != nil {
+			o.Progress(r)
+		}
+	}
+
+	var pending []Row
+	for _, row := range rows {
+		if msg, ok := invalid[row.Line]; ok {
+			record(Result{Line: row.Line, SerialNumber: row.SerialNumber, Status: Invalid, Error: msg}, false)
+			continue
+		}
+		if prev, ok := done[row.SerialNumber]; ok {
+			prev.Line = row.Line
+			prev.Resumed = true
+			record(prev, false)
+			continue
+		}
+		pending = append(pending, row)
+	}
+	pool.Run(ctx, o.Workers, pending, func(row Row) {
+		record(o.onboard(ctx, row), true)
+	})
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, cpErr
+}
+
+func (o *Onboarder) onboard(ctx context.Context, row Row) Result {
+	res := Result{Line: row.Line, SerialNumber: row.SerialNumber}
+
+	nt := &amp360.NewTerminal{
+		ModelID:      row.ModelID,
+		SerialNumber: row.SerialNumber,
+		Name:         row.Name,
+		TemplateID:   row.TemplateID,
+		Parameters:   map[string]interface{}{},
+	}
+	if row.ClientID != "" {
+		nt.ClientID = row.ClientID
+	}
+	for k, v := range row.Params {
+		nt.Parameters[k] = v
+	}
+
+	ct, err := o.Client.TerminalsService.Add(ctx, nt)
+	switch {
+	case err == nil:
+		res.Status, res.TerminalID = Created, ct.ID
+		return res
+	case !errors.Is(err, amp360.ErrConflict) || o.OnConflict == Fail:
+		res.Status, res.Error = Failed, err.Error()
+		return res
+	}
+
+	tl, err := o.Client.TerminalsService.List(ctx, &amp360.TerminalsOpt{SerialNumber: row.SerialNumber})
+	if err != nil {
+		res.Status, res.Error = Failed, err.Error()
+		return res
+	}
+	if len(tl.Rows) == 0 {
+		res.Status, res.Error = Failed, "conflicting terminal not found"
+		return res
+	}
+	res.TerminalID = tl.Rows[0].ID
+	if o.OnConflict == Skip {
+		res.Status = Skipped
+		return res
+	}
+
+	if row.Name != "" || row.ClientID != "" {
+		upd := &amp360.TerminalUpdate{Name: row.Name, ClientID: row.ClientID}
+		if err := o.Client.TerminalsService.Edit(ctx, res.TerminalID, upd); err != nil {
+			res.Status, res.Error = Failed, err.Error()
+			return res
+		}
+	}
+	if len(row.Params) > 0 {
+		br, err := o.Client.TerminalsService.SetParams(ctx, res.TerminalID, row.Params, nil)
+		if err != nil {
+			res.Status, res.Error = Failed, err.Error()
+			return res
+		}
+		if len(br.Failed) > 0 {
+			res.Status, res.Error = Failed, fmt.Sprintf("parameters not updated: %v", br.Failed)
+			return res
+		}
+	}
+	res.Status = Updated
+	return res
+}
+
+// loadCheckpoint returns the completed rows recorded at path by serial
+// number.
+func loadCheckpoint(path string) (map[string]Result, error) {
+	done := map[string]Result{}
+	if path == "" {
+		return done, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		r := Result{}
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			// a run killed mid-write leaves a truncated last line
+			continue
+		}
+		done[r.SerialNumber] = r
+	}
+	return done, sc.Err()
+}
+
+// WriteReport writes results as CSV.
+func WriteReport(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"line", "serial_number", "status", "terminal_id", "resumed", "error"})
+	for _, r := range results {
+		id := ""
+		if r.TerminalID != 0 {
+			id = strconv.Itoa(r.TerminalID)
+		}
+		cw.Write([]string{strconv.Itoa(r.Line), r.SerialNumber, string(r.Status), id, strconv.FormatBool(r.Resumed), r.Error})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,137 @@
+package onboard
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+)
+
+func newServer(t *testing.T) *amp360test.Server {
+	t.Helper()
+	srv := amp360test.NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{{
+			ID: 814,
+			Params: []amp360.Param{
+				{Tag: amp360test.DefaultTIDTag},
+				{Tag: amp360test.DefaultMIDTag},
+			},
+		}},
+		Terminals: []amp360test.Terminal{{ID: 25, SerialNumber: "8000000002", Name: "old", ModelID: "m1", TemplateID: 814}},
+	})
+	return srv
+}
+
+func manifestRows(t *testing.T) []Row {
+	t.Helper()
+	rows, err := ReadCSV(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestRun(t *testing.T) {
+	srv := newServer(t)
+	o := &Onboarder{Client: srv.Client(), Workers: 2, OnConflict: Skip}
+
+	results, err := o.Run(context.Background(), manifestRows(t))
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	want := []Status{Created, Skipped, Invalid, Invalid}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("line %d status got %v, want %v (%s)", r.Line, r.Status, want[i], r.Error)
+		}
+	}
+	if results[1].TerminalID != 25 {
+		t.Errorf("skipped terminal ID got %v, want %v", results[1].TerminalID, 25)
+	}
+	if term, _ := srv.Terminal(25); term.Name != "old" {
+		t.Errorf("skipped terminal renamed to %v", term.Name)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteReport(buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "3,8000000002,skipped,25,false,") {
+		t.Errorf("report got:\n%s", buf)
+	}
+}
+
+func TestRunUpdate(t *testing.T) {
+	srv := newServer(t)
+	o := &Onboarder{Client: srv.Client(), OnConflict: Update}
+
+	results, err := o.Run(context.Background(), manifestRows(t)[:2])
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if results[1].Status != Updated {
+		t.Errorf("status got %v, want %v (%s)", results[1].Status, Updated, results[1].Error)
+	}
+	term, _ := srv.Terminal(25)
+	if term.Name != "Shop 2" || term.SerialNumber != "8000000002" || term.ModelID != "m1" ||
+		term.Params[amp360test.DefaultTIDTag] != "10000002" {
+		t.Errorf("updated terminal got %+v", term)
+	}
+}
+
+func TestRunFail(t *testing.T) {
+	srv := newServer(t)
+	o := &Onboarder{Client: srv.Client(), OnConflict: Fail}
+
+	results, _ := o.Run(context.Background(), manifestRows(t)[1:2])
+	if results[0].Status != Failed || !strings.Contains(results[0].Error, "409") {
+		t.Errorf("result got %+v", results[0])
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	srv := newServer(t)
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	rows := manifestRows(t)[:2]
+
+	// the first run stops after the first row
+	o := &Onboarder{Client: srv.Client(), Workers: 1, Checkpoint: checkpoint}
+	results, err := o.Run(context.Background(), rows[:1])
+	if err != nil || results[0].Status != Created {
+		t.Fatalf("first run got %+v, error %v", results, err)
+	}
+
+	requests := srv.Requests()
+	results, err = o.Run(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if !results[0].Resumed || results[0].Status != Created {
+		t.Errorf("first row got %+v, want resumed", results[0])
+	}
+	if results[1].Resumed || results[1].Status != Skipped {
+		t.Errorf("second row got %+v", results[1])
+	}
+	// only the second row hits the server: create and lookup
+	if got := srv.Requests() - requests; got != 2 {
+		t.Errorf("requests got %v, want %v", got, 2)
+	}
+	if n := len(srv.Terminals()); n != 2 {
+		t.Errorf("terminals got %v, want %v", n, 2)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	if p, err := ParsePolicy("update"); err != nil || p != Update {
+		t.Errorf("ParsePolicy got %v, %v", p, err)
+	}
+	if _, err := ParsePolicy("merge"); err == nil {
+		t.Error("Error is nil for unknown policy")
+	}
+}
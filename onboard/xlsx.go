@@ -0,0 +1,161 @@
+package onboard
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ReadXLSX reads the first worksheet of an XLSX manifest.
+func ReadXLSX(name string) ([]Row, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	records, err := readSheet(&zr.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("onboard: %s: %w", name, err)
+	}
+	return parseRecords(records)
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRels struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSST struct {
+	Items []struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Num   int `xml:"r,attr"` // 1 based, rows without cells may be left out
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXML(zr *zip.Reader, name string, v interface{}) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
+
+func readSheet(zr *zip.Reader) ([]record, error) {
+	wb := xlsxWorkbook{}
+	if err := readXML(zr, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, errors.New("no worksheet")
+	}
+	rels := xlsxRels{}
+	if err := readXML(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, r := range rels.Rels {
+		if r.ID == wb.Sheets[0].RID {
+			sheetPath = r.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, errors.New("first worksheet not found")
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	var shared []string
+	sst := xlsxSST{}
+	if err := readXML(zr, "xl/sharedStrings.xml", &sst); err == nil {
+		for _, si := range sst.Items {
+			s := si.T
+			for _, r := range si.Runs {
+				s += r.T
+			}
+			shared = append(shared, s)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	sheet := xlsxSheet{}
+	if err := readXML(zr, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+	records := make([]record, 0, len(sheet.Rows))
+	line := 0
+	for _, row := range sheet.Rows {
+		line++
+		if row.Num > 0 {
+			line = row.Num
+		}
+		rec := []string{}
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(rec) <= col {
+				rec = append(rec, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("cell %s: invalid shared string %q", c.Ref, c.Value)
+				}
+				rec[col] = shared[n]
+			case "inlineStr":
+				rec[col] = c.Inline
+			default:
+				rec[col] = c.Value
+			}
+		}
+		records = append(records, record{line: line, fields: rec})
+	}
+	return records, nil
+}
+
+// columnIndex returns the zero based column of a cell reference like "AB12".
+func columnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
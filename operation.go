@@ -0,0 +1,69 @@
+package amp360
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// Names of the operations of the services, labelling their metrics and
+// spans.
+const (
+	OpCompaniesList       = "companies.list"
+	OpModelsList          = "models.list"
+	OpTemplatesList       = "templates.list"
+	OpTemplatesParamsGet  = "templates.params.get"
+	OpTemplatesParamsBulk = "templates.params.bulk"
+	OpTerminalsList       = "terminals.list"
+	OpTerminalsDetails    = "terminals.details"
+	OpTerminalsCreate     = "terminals.create"
+	OpTerminalsUpdate     = "terminals.update"
+	OpTerminalsDelete     = "terminals.delete"
+	OpTerminalsParamsGet  = "terminals.params.get"
+	OpTerminalsParamsBulk = "terminals.params.bulk"
+	OpFilesDownload       = "files.download"
+)
+
+type operationKey struct{}
+
+// operation is the logical operation of the calls made with a context.
+type operation struct {
+	name  string // label of the metrics
+	span  string // name of the span
+	attrs []Attribute
+}
+
+// WithOperation names the operation of the calls made with ctx. The services
+// name their calls themselves; it is meant for the requests sent with Do.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return withOperation(ctx, name, name)
+}
+
+// withOperation names the operation of the calls made with ctx, traced in
+// spans named span carrying attrs.
+func withOperation(ctx context.Context, name, span string, attrs ...Attribute) context.Context {
+	return context.WithValue(ctx, operationKey{}, &operation{name: name, span: span, attrs: attrs})
+}
+
+// OperationFrom returns the operation named in ctx, if any.
+func OperationFrom(ctx context.Context) string {
+	if op, ok := ctx.Value(operationKey{}).(*operation); ok {
+		return op.name
+	}
+	return ""
+}
+
+// operation returns the operation of a call to u: the one of ctx, else the
+// first segment of the path relative to the base URL followed by the
+// method, e.g. "terminals.get".
+func (c *Client) operation(ctx context.Context, method string, u *url.URL) string {
+	if name := OperationFrom(ctx); name != "" {
+		return name
+	}
+	p := strings.TrimPrefix(u.Path, c.BaseURL.Path)
+	resource, _, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	if resource == "" {
+		resource = "unknown"
+	}
+	return resource + "." + strings.ToLower(method)
+}
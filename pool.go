@@ -0,0 +1,284 @@
+package amp360
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Tenant is a company managed with its own API key. The key is APIKey, the
+// content of APIKeyFile or the environment variable APIKeyEnv, in that
+// order.
+type Tenant struct {
+	Name       string `json:"name" yaml:"name"`
+	CompanyID  string `json:"companyId,omitempty" yaml:"company_id,omitempty"`
+	APIKey     string `json:"apiKey,omitempty" yaml:"api_key,omitempty"`
+	APIKeyFile string `json:"apiKeyFile,omitempty" yaml:"api_key_file,omitempty"`
+	APIKeyEnv  string `json:"apiKeyEnv,omitempty" yaml:"api_key_env,omitempty"`
+}
+
+func (t Tenant) credentials() (CredentialsProvider, error) {
+	switch {
+	case t.APIKey != "":
+		return StaticCredentials(t.APIKey), nil
+	case t.APIKeyFile != "":
+		return NewFileCredentials(t.APIKeyFile), nil
+	case t.APIKeyEnv != "":
+		return EnvCredentials(t.APIKeyEnv), nil
+	}
+	return nil, fmt.Errorf("tenant %s: %w", t.Name, ErrNoCredentials)
+}
+
+// ReadTenants reads a JSON array of tenants.
+func ReadTenants(r io.Reader) ([]Tenant, error) {
+	var tenants []Tenant
+	if err := json.NewDecoder(r).Decode(&tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// ClientPool holds a client per tenant. The clients share one http.Client
+// and the rate limiter, retry policy and logger set on the pool.
+type ClientPool struct {
+	baseURL string
+	http    *http.Client
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+	tenants map[string]Tenant
+	limiter *RateLimiter
+	retry   *RetryPolicy
+	logger  Logger
+	metrics Metrics
+	tracer  Tracer
+}
+
+// NewClientPool returns an empty pool of clients of the API at baseURL,
+// which NewClient interprets, sending requests through httpClient.
+func NewClientPool(baseURL string, httpClient *http.Client) *ClientPool {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &ClientPool{
+		baseURL: baseURL,
+		http:    httpClient,
+		clients: map[string]*Client{},
+		tenants: map[string]Tenant{},
+	}
+}
+
+// Load adds tenants to the pool.
+func (p *ClientPool) Load(tenants []Tenant) error {
+	for _, t := range tenants {
+		if _, err := p.Add(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add creates the client of tenant t.
+func (p *ClientPool) Add(t Tenant) (*Client, error) {
+	if t.Name == "" {
+		return nil, errors.New("required tenant name is missing")
+	}
+	creds, err := t.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.clients[t.Name]; ok {
+		return nil, fmt.Errorf("tenant %s: %w", t.Name, ErrConflict)
+	}
+	c := NewClient(p.baseURL, p.http)
+	c.SetCredentials(creds)
+	c.SetRateLimiter(p.limiter)
+	c.SetRetryPolicy(p.retry)
+	c.SetLogger(p.logger)
+	c.SetMetrics(p.metrics)
+	c.SetTracer(p.tracer)
+	p.clients[t.Name] = c
+	p.tenants[t.Name] = t
+	return c, nil
+}
+
+// Remove drops the client of tenant name.
+func (p *ClientPool) Remove(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, name)
+	delete(p.tenants, name)
+}
+
+// Client returns the client of tenant name.
+func (p *ClientPool) Client(name string) (*Client, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	c, ok := p.clients[name]
+	return c, ok
+}
+
+// Tenant returns the configuration of tenant name.
+func (p *ClientPool) Tenant(name string) (Tenant, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	t, ok := p.tenants[name]
+	return t, ok
+}
+
+// Tenants returns the sorted names of the tenants.
+func (p *ClientPool) Tenants() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetRateLimit limits the requests of all the tenants together to rps per
+// second with bursts of up to burst. A non-positive rps removes the limit.
+func (p *ClientPool) SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		p.SetRateLimiter(nil)
+		return
+	}
+	p.SetRateLimiter(NewRateLimiter(rps, burst))
+}
+
+// SetRateLimiter sets the limiter shared by all the clients.
+func (p *ClientPool) SetRateLimiter(l *RateLimiter) {
+	p.each(func(c *Client) { c.SetRateLimiter(l) }, func() { p.limiter = l })
+}
+
+// SetRetryPolicy sets the retry policy of all the clients.
+func (p *ClientPool) SetRetryPolicy(policy *RetryPolicy) {
+	p.each(func(c *Client) { c.SetRetryPolicy(policy) }, func() { p.retry = policy })
+}
+
+// SetLogger sets the logger of all the clients.
+func (p *ClientPool) SetLogger(l Logger) {
+	p.each(func(c *Client) { c.SetLogger(l) }, func() { p.logger = l })
+}
+
+// SetMetrics sets the metrics of all the clients.
+func (p *ClientPool) SetMetrics(m Metrics) {
+	p.each(func(c *Client) { c.SetMetrics(m) }, func() { p.metrics = m })
+}
+
+// SetTracer sets the tracer of all the clients.
+func (p *ClientPool) SetTracer(t Tracer) {
+	p.each(func(c *Client) { c.SetTracer(t) }, func() { p.tracer = t })
+}
+
+func (p *ClientPool) each(fn func(*Client), set func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	set()
+	for _, c := range p.clients {
+		fn(c)
+	}
+}
+
+// TenantError is the error of a call made for a tenant.
+type TenantError struct {
+	Tenant string
+	Err    error
+}
+
+func (e *TenantError) Error() string {
+	return fmt.Sprintf("tenant %s: %v", e.Tenant, e.Err)
+}
+
+func (e *TenantError) Unwrap() error {
+	return e.Err
+}
+
+// TenantResult is the result of a call made for a tenant.
+type TenantResult[T any] struct {
+	Tenant string
+	Value  T
+	Err    error
+}
+
+// FanOut calls fn for every tenant of p, running up to concurrency calls at
+// once, all of them if zero. The results are sorted by tenant.
+func FanOut[T any](ctx context.Context, p *ClientPool, concurrency int, fn func(ctx context.Context, tenant string, c *Client) (T, error)) []TenantResult[T] {
+	names := p.Tenants()
+	if concurrency <= 0 || concurrency > len(names) {
+		concurrency = len(names)
+	}
+	results := make([]TenantResult[T], len(names))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		results[i].Tenant = name
+		c, ok := p.Client(name)
+		if !ok {
+			results[i].Err = &TenantError{Tenant: name, Err: ErrEntityNotFound}
+			continue
+		}
+		wg.Add(1)
+		go func(r *TenantResult[T], c *Client) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				r.Err = &TenantError{Tenant: r.Tenant, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+			v, err := fn(ctx, r.Tenant, c)
+			r.Value = v
+			if err != nil {
+				r.Err = &TenantError{Tenant: r.Tenant, Err: err}
+			}
+		}(&results[i], c)
+	}
+	wg.Wait()
+	return results
+}
+
+// TenantTerminal is a terminal of a tenant.
+type TenantTerminal struct {
+	Tenant   string    `json:"tenant"`
+	Terminal *Terminal `json:"terminal"`
+}
+
+// Terminals lists the terminals matching opt of every tenant concurrently,
+// following all the pages. The terminals of the tenants that failed are
+// missing from the result and the returned error joins their *TenantError.
+func (p *ClientPool) Terminals(ctx context.Context, opt *TerminalsOpt) ([]TenantTerminal, error) {
+	results := FanOut(ctx, p, 0, func(ctx context.Context, _ string, c *Client) ([]*Terminal, error) {
+		var terminals []*Terminal
+		it := c.TerminalsService.All(ctx, opt)
+		for it.Next() {
+			t := it.Value()
+			terminals = append(terminals, &t)
+		}
+		return terminals, it.Err()
+	})
+
+	var all []TenantTerminal
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+		for _, t := range r.Value {
+			all = append(all, TenantTerminal{Tenant: r.Tenant, Terminal: t})
+		}
+	}
+	return all, errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientPoolTerminalsMock(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "key-a":
+			fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":2,"rows":[{"id":1,"serialNumber":"A1"},{"id":2,"serialNumber":"A2"}]}}`)
+		case "key-b":
+			fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":1,"rows":[{"id":3,"serialNumber":"B1"}]}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"success":false,"message":"unauthorized","data":{}}`)
+		}
+	})
+
+	hc := &http.Client{}
+	p := NewClientPool(srv.URL+"/", hc)
+	p.SetRateLimit(100, 10)
+	tenants, err := ReadTenants(strings.NewReader(`[{"name":"a","apiKey":"key-a"},{"name":"b","apiKey":"key-b"},{"name":"c","apiKey":"wrong"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Load(tenants); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+
+	a, _ := p.Client("a")
+	b, _ := p.Client("b")
+	if a.client != hc || b.client != hc || a.RateLimiter() == nil || a.RateLimiter() != b.RateLimiter() {
+		t.Error("clients don't share the http.Client and the rate limiter")
+	}
+
+	terminals, err := p.Terminals(context.Background(), nil)
+	var tenantErr *TenantError
+	if !errors.As(err, &tenantErr) || tenantErr.Tenant != "c" || !errors.Is(err, ErrIvalidToken) {
+		t.Errorf("Error got %v, want a tenant error of c", err)
+	}
+	got := []string{}
+	for _, tt := range terminals {
+		got = append(got, tt.Tenant+":"+tt.Terminal.SerialNumber)
+	}
+	if strings.Join(got, ",") != "a:A1,a:A2,b:B1" {
+		t.Errorf("terminals got %v", got)
+	}
+}
+
+func TestClientPoolAdd(t *testing.T) {
+	p := NewClientPool("", nil)
+	if _, err := p.Add(Tenant{Name: "a", APIKeyEnv: "TEST_AMP360_KEY"}); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if _, err := p.Add(Tenant{Name: "a", APIKey: "x"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Error got %v, want %v", err, ErrConflict)
+	}
+	if _, err := p.Add(Tenant{Name: "b"}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Error got %v, want %v", err, ErrNoCredentials)
+	}
+
+	policy := DefaultRetryPolicy()
+	p.SetRetryPolicy(policy)
+	c, _ := p.Client("a")
+	if c.retryPolicy() != policy {
+		t.Error("retry policy not applied to the existing client")
+	}
+	p.Remove("a")
+	if len(p.Tenants()) != 0 {
+		t.Errorf("Tenants got %v", p.Tenants())
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	p := NewClientPool("", nil)
+	for _, name := range []string{"c", "a", "b"} {
+		if _, err := p.Add(Tenant{Name: name, APIKey: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results := FanOut(context.Background(), p, 2, func(ctx context.Context, tenant string, c *Client) (string, error) {
+		if tenant == "b" {
+			return "", ErrNoPermission
+		}
+		return strings.ToUpper(tenant), nil
+	})
+	if len(results) != 3 || results[0].Value != "A" || results[2].Value != "C" || !errors.Is(results[1].Err, ErrNoPermission) {
+		t.Errorf("results got %+v", results)
+	}
+}
@@ -0,0 +1,206 @@
+package amp360
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of requests sent to the
+// API. A single limiter may be shared by several clients. It adapts to the
+// X-RateLimit-* and Retry-After headers sent by the server.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // configured tokens per second
+	burst  int
+	tokens float64
+	last   time.Time
+
+	// adaptive state learned from the server
+	adapted         float64 // rate derived from rate-limit headers, 0 if none
+	blockedUntil    time.Time
+	serverLimit     int
+	serverRemaining int
+	serverReset     time.Time
+}
+
+// RateLimitState is a snapshot of a RateLimiter.
+type RateLimitState struct {
+	Rate            float64   // effective requests per second
+	Burst           int       // bucket size
+	Tokens          float64   // requests that can be sent without waiting
+	BlockedUntil    time.Time // requests are held until this time, if set
+	ServerLimit     int       // last X-RateLimit-Limit, -1 if unknown
+	ServerRemaining int       // last X-RateLimit-Remaining, -1 if unknown
+	ServerReset     time.Time // last X-RateLimit-Reset, zero if unknown
+}
+
+// NewRateLimiter returns a limiter allowing rps requests per second with
+// bursts of up to burst requests.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:            rps,
+		burst:           burst,
+		tokens:          float64(burst),
+		last:            time.Now(),
+		serverLimit:     -1,
+		serverRemaining: -1,
+	}
+}
+
+// SetRate changes the configured rate and burst of the limiter.
+func (l *RateLimiter) SetRate(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if burst < 1 {
+		burst = 1
+	}
+	l.rate = rps
+	l.burst = burst
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+}
+
+// State returns the current state of the limiter.
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.refill(now)
+	s := RateLimitState{
+		Rate:            l.effectiveRate(),
+		Burst:           l.burst,
+		Tokens:          math.Max(l.tokens, 0),
+		ServerLimit:     l.serverLimit,
+		ServerRemaining: l.serverRemaining,
+		ServerReset:     l.serverReset,
+	}
+	if l.blockedUntil.After(now) {
+		s.BlockedUntil = l.blockedUntil
+	}
+	return s
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	var delay time.Duration
+	if l.blockedUntil.After(now) {
+		delay = l.blockedUntil.Sub(now)
+	}
+	l.tokens--
+	if l.tokens < 0 {
+		if rate := l.effectiveRate(); rate > 0 {
+			if d := time.Duration(-l.tokens / rate * float64(time.Second)); d > delay {
+				delay = d
+			}
+		}
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) effectiveRate() float64 {
+	if l.adapted > 0 && (l.rate <= 0 || l.adapted < l.rate) {
+		return l.adapted
+	}
+	return l.rate
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	if rate := l.effectiveRate(); rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	} else {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+}
+
+// update adjusts the limiter to the rate-limit headers of res.
+func (l *RateLimiter) update(res *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.refill(now)
+
+	if v, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit")); err == nil {
+		l.serverLimit = v
+	}
+	if v, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining")); err == nil {
+		l.serverRemaining = v
+	}
+	if v, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		// small values are a delay in seconds, large ones a unix timestamp
+		if v < 1e9 {
+			l.serverReset = now.Add(time.Duration(v) * time.Second)
+		} else {
+			l.serverReset = time.Unix(v, 0)
+		}
+	}
+
+	l.adapted = 0
+	if l.serverRemaining >= 0 && l.serverReset.After(now) {
+		if l.serverRemaining == 0 {
+			l.blockedUntil = l.serverReset
+		} else {
+			l.adapted = float64(l.serverRemaining) / l.serverReset.Sub(now).Seconds()
+		}
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		if after := retryAfter(res.Header.Get("Retry-After")); after > 0 {
+			l.blockedUntil = now.Add(after)
+		}
+	}
+}
+
+// SetRateLimit limits the client to rps requests per second with bursts of
+// up to burst requests. A non-positive rps removes the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		c.SetRateLimiter(nil)
+		return
+	}
+	c.SetRateLimiter(NewRateLimiter(rps, burst))
+}
+
+// SetRateLimiter sets the limiter used by the client, allowing several
+// clients to share one budget. A nil limiter removes the limit.
+func (c *Client) SetRateLimiter(l *RateLimiter) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.limiter = l
+}
+
+// RateLimiter returns the limiter used by the client, nil if unlimited.
+func (c *Client) RateLimiter() *RateLimiter {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.limiter
+}
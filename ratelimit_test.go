@@ -0,0 +1,83 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Error occured = %v", err)
+		}
+	}
+	// two requests come from the burst, two more need 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("elapsed got %v, want at least %v", elapsed, 15*time.Millisecond)
+	}
+}
+
+func TestRateLimiterContextCanceled(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Error got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if s := l.State(); s.Tokens > 1 {
+		t.Errorf("Tokens got %v, want at most 1", s.Tokens)
+	}
+}
+
+func TestRateLimiterHeadersMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", "20")
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":0,"rows":[]}}`)
+	})
+	c.SetRateLimit(50, 5)
+
+	err := c.ModelsService.GetList(context.Background(), &ModelsList{})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+
+	s := c.RateLimiter().State()
+	if s.ServerLimit != 100 || s.ServerRemaining != 10 {
+		t.Errorf("server state got %+v", s)
+	}
+	// 10 requests left over 20 seconds
+	if s.Rate > 1 {
+		t.Errorf("Rate got %v, want at most 1", s.Rate)
+	}
+	if s.Burst != 5 {
+		t.Errorf("Burst got %v, want %v", s.Burst, 5)
+	}
+}
+
+func TestRateLimiterTooManyRequests(t *testing.T) {
+	l := NewRateLimiter(10, 1)
+	res := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"30"}},
+	}
+	l.update(res)
+	if s := l.State(); s.BlockedUntil.Before(time.Now().Add(29 * time.Second)) {
+		t.Errorf("BlockedUntil got %v, want about 30s from now", s.BlockedUntil)
+	}
+}
@@ -0,0 +1,173 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// Status is the outcome of an action.
+type Status string
+
+const (
+	Applied Status = "applied"
+	Failed  Status = "failed"
+	// Skipped is an action not run because an earlier action on the same
+	// terminal failed.
+	Skipped Status = "skipped"
+)
+
+// Result is the outcome of applying an action.
+type Result struct {
+	Action       ActionType `json:"action"`
+	SerialNumber string     `json:"serialNumber"`
+	TerminalID   int        `json:"terminalId,omitempty"`
+	Status       Status     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Reconciler plans and applies the changes that bring the live terminals to
+// a desired state.
+type Reconciler struct {
+	Client *amp360.Client
+	// Scope narrows the live terminals compared with the desired state, and
+	// deleted when Prune is set.
+	Scope *amp360.TerminalsOpt
+	// Prune deletes the live terminals missing from the desired state.
+	Prune bool
+	// Workers is the number of terminals processed concurrently, 4 if zero.
+	Workers int
+	// Progress, if set, is called after each action is applied.
+	Progress func(Result)
+}
+
+// Apply runs the actions of p. The actions on a terminal run in order and
+// stop at the first failure; terminals are processed concurrently. The
+// results are in the order of the plan.
+func (r *Reconciler) Apply(ctx context.Context, p *Plan) ([]Result, error) {
+	var groups [][]int
+	last := ""
+	for i, a := range p.Actions {
+		if i == 0 || a.SerialNumber != last {
+			groups = append(groups, nil)
+			last = a.SerialNumber
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+
+	var (
+		mu      sync.Mutex
+		results = make([]Result, len(p.Actions))
+	)
+	n := pool.Run(ctx, r.Workers, groups, func(group []int) {
+		failed := false
+		for _, i := range group {
+			a := &p.Actions[i]
+			res := Result{Action: a.Type, SerialNumber: a.SerialNumber, TerminalID: a.TerminalID, Status: Skipped}
+			if !failed {
+				res = r.apply(ctx, a)
+				failed = res.Status == Failed
+			}
+			mu.Lock()
+			results[i] = res
+			if r.Progress != nil {
+				r.Progress(res)
+			}
+			mu.Unlock()
+		}
+	})
+
+	if err := ctx.Err(); err != nil {
+		done := 0
+		for _, g := range groups[:n] {
+			done += len(g)
+		}
+		return results[:done], err
+	}
+	return results, nil
+}
+
+func (r *Reconciler) apply(ctx context.Context, a *Action) Result {
+	res := Result{Action: a.Type, SerialNumber: a.SerialNumber, TerminalID: a.TerminalID}
+	var err error
+	switch a.Type {
+	case Create:
+		var ct *amp360.CreatedTerminal
+		if ct, err = r.Client.TerminalsService.Add(ctx, newTerminal(a)); err == nil {
+			res.TerminalID = ct.ID
+		}
+	case Update:
+		upd := &amp360.TerminalUpdate{}
+		for _, c := range a.Changes {
+			switch c.Field {
+			case "name":
+				upd.Name = c.To
+			case "client":
+				upd.ClientID = c.To
+			case "template":
+				upd.TemplateID = c.To
+			}
+		}
+		err = r.Client.TerminalsService.Edit(ctx, a.TerminalID, upd)
+	case Params:
+		params := make(map[string]string, len(a.Changes))
+		for _, c := range a.Changes {
+			if tag, ok := strings.CutPrefix(c.Field, paramPrefix); ok {
+				params[tag] = c.To
+			}
+		}
+		var br *amp360.BulkResult
+		if br, err = r.Client.TerminalsService.SetParams(ctx, a.TerminalID, params, nil); err == nil && len(br.Failed) > 0 {
+			err = fmt.Errorf("parameters not updated: %v", br.Failed)
+		}
+	case Delete:
+		err = r.Client.TerminalsService.Delete(ctx, a.TerminalID)
+	default:
+		err = fmt.Errorf("unknown action %q", a.Type)
+	}
+
+	if err != nil {
+		res.Status, res.Error = Failed, err.Error()
+		return res
+	}
+	res.Status = Applied
+	return res
+}
+
+// newTerminal builds the terminal created by a.
+func newTerminal(a *Action) *amp360.NewTerminal {
+	nt := &amp360.NewTerminal{SerialNumber: a.SerialNumber, Parameters: map[string]interface{}{}}
+	for _, c := range a.Changes {
+		switch c.Field {
+		case "name":
+			nt.Name = c.To
+		case "model":
+			nt.ModelID = c.To
+		case "client":
+			nt.ClientID = c.To
+		case "template":
+			nt.TemplateID = c.To
+		default:
+			if tag, ok := strings.CutPrefix(c.Field, paramPrefix); ok {
+				nt.Parameters[tag] = c.To
+			}
+		}
+	}
+	return nt
+}
+
+// Summary counts the results by action and status.
+func Summary(results []Result) map[ActionType]map[Status]int {
+	m := map[ActionType]map[Status]int{}
+	for _, r := range results {
+		if m[r.Action] == nil {
+			m[r.Action] = map[Status]int{}
+		}
+		m[r.Action][r.Status]++
+	}
+	return m
+}
@@ -0,0 +1,271 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// ActionType is the kind of change an action makes.
+type ActionType string
+
+const (
+	Create ActionType = "create"
+	Update ActionType = "update"
+	Params ActionType = "params"
+	Delete ActionType = "delete"
+)
+
+const paramPrefix = "params."
+
+// Change is a field changed by an action: name, model, client, template,
+// or params.<tag> for a parameter. From is empty for creates and To for
+// deletes.
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// Action is a change to a terminal.
+type Action struct {
+	Type         ActionType `json:"type"`
+	SerialNumber string     `json:"serialNumber"`
+	TerminalID   int        `json:"terminalId,omitempty"` // zero for creates
+	Changes      []Change   `json:"changes,omitempty"`
+}
+
+// Plan is the list of actions that bring the live state to the desired
+// state, sorted by serial number. Actions on a terminal are applied in
+// order.
+type Plan struct {
+	Actions  []Action `json:"actions"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Empty reports whether the live state matches the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Count returns the number of actions of type t.
+func (p *Plan) Count(t ActionType) int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+var symbols = map[ActionType]string{Create: "+", Update: "~", Params: "~", Delete: "-"}
+
+// Write prints the plan in the style of terraform plan.
+func (p *Plan) Write(w io.Writer) error {
+	ew := &errWriter{w: w}
+	for _, warn := range p.Warnings {
+		ew.printf("Warning: %s\n", warn)
+	}
+	if len(p.Warnings) > 0 {
+		ew.printf("\n")
+	}
+	if p.Empty() {
+		ew.printf("No changes. The terminals match the desired state.\n")
+		return ew.err
+	}
+
+	for _, a := range p.Actions {
+		sym := symbols[a.Type]
+		what := "terminal"
+		if a.Type == Params {
+			what = "terminal parameters"
+		}
+		if a.TerminalID != 0 {
+			ew.printf("  %s %s %s (id %d)\n", sym, what, a.SerialNumber, a.TerminalID)
+		} else {
+			ew.printf("  %s %s %s\n", sym, what, a.SerialNumber)
+		}
+		for _, c := range a.Changes {
+			switch a.Type {
+			case Create:
+				ew.printf("      + %s = %q\n", c.Field, c.To)
+			case Delete:
+				ew.printf("      - %s = %q\n", c.Field, c.From)
+			default:
+				ew.printf("      ~ %s: %q -> %q\n", c.Field, c.From, c.To)
+			}
+		}
+		ew.printf("\n")
+	}
+	ew.printf("Plan: %d to create, %d to update, %d to change parameters, %d to delete.\n",
+		p.Count(Create), p.Count(Update), p.Count(Params), p.Count(Delete))
+	return ew.err
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
+
+// live is what the plan needs of an existing terminal.
+type live struct {
+	id         int
+	name       string
+	clientID   string
+	templateID string
+	modelID    string
+}
+
+// Plan compares s with the live terminals and returns the actions that
+// reconcile them. Parameters are compared only for the terminals whose
+// desired state sets some.
+func (r *Reconciler) Plan(ctx context.Context, s *State) (*Plan, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	current := map[string]live{}
+	it := r.Client.TerminalsService.All(ctx, r.Scope)
+	for it.Next() {
+		t := it.Value()
+		current[t.SerialNumber] = live{
+			id:         t.ID,
+			name:       t.Name,
+			clientID:   t.ClientID,
+			templateID: strconv.Itoa(t.AppTemplateID),
+			modelID:    t.TerminalModelID,
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	params, err := r.liveParams(ctx, s, current)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Actions: []Action{}}
+	desired := map[string]bool{}
+	for i := range s.Terminals {
+		t := &s.Terminals[i]
+		desired[t.SerialNumber] = true
+		cur, ok := current[t.SerialNumber]
+		if !ok {
+			p.Actions = append(p.Actions, createAction(t))
+			continue
+		}
+
+		if t.ModelID != "" && t.ModelID != cur.modelID {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("terminal %s: model %s can't be changed to %s", t.SerialNumber, cur.modelID, t.ModelID))
+		}
+		var changes []Change
+		if t.Name != "" && t.Name != cur.name {
+			changes = append(changes, Change{Field: "name", From: cur.name, To: t.Name})
+		}
+		if t.ClientID != "" && t.ClientID != cur.clientID {
+			changes = append(changes, Change{Field: "client", From: cur.clientID, To: t.ClientID})
+		}
+		if t.TemplateID != "" && t.TemplateID != cur.templateID {
+			changes = append(changes, Change{Field: "template", From: cur.templateID, To: t.TemplateID})
+		}
+		if len(changes) > 0 {
+			p.Actions = append(p.Actions, Action{Type: Update, SerialNumber: t.SerialNumber, TerminalID: cur.id, Changes: changes})
+		}
+
+		changes = nil
+		for _, tag := range sortedKeys(t.Params) {
+			if from, ok := params[t.SerialNumber][tag]; !ok || from != t.Params[tag] {
+				changes = append(changes, Change{Field: paramPrefix + tag, From: from, To: t.Params[tag]})
+			}
+		}
+		if len(changes) > 0 {
+			p.Actions = append(p.Actions, Action{Type: Params, SerialNumber: t.SerialNumber, TerminalID: cur.id, Changes: changes})
+		}
+	}
+
+	if r.Prune {
+		for serial, cur := range current {
+			if !desired[serial] {
+				p.Actions = append(p.Actions, Action{Type: Delete, SerialNumber: serial, TerminalID: cur.id, Changes: []Change{{Field: "name", From: cur.name}}})
+			}
+		}
+	}
+
+	sort.SliceStable(p.Actions, func(i, j int) bool { return p.Actions[i].SerialNumber < p.Actions[j].SerialNumber })
+	return p, nil
+}
+
+func createAction(t *Terminal) Action {
+	a := Action{Type: Create, SerialNumber: t.SerialNumber}
+	for _, c := range []Change{{"name", "", t.Name}, {"model", "", t.ModelID}, {"client", "", t.ClientID}, {"template", "", t.TemplateID}} {
+		if c.To != "" {
+			a.Changes = append(a.Changes, c)
+		}
+	}
+	for _, tag := range sortedKeys(t.Params) {
+		a.Changes = append(a.Changes, Change{Field: paramPrefix + tag, To: t.Params[tag]})
+	}
+	return a
+}
+
+// liveParams fetches the parameters of the existing terminals whose desired
+// state sets some, by serial number.
+func (r *Reconciler) liveParams(ctx context.Context, s *State, current map[string]live) (map[string]map[string]string, error) {
+	var terminals []Terminal
+	for _, t := range s.Terminals {
+		if _, ok := current[t.SerialNumber]; ok && len(t.Params) > 0 {
+			terminals = append(terminals, t)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		params   = map[string]map[string]string{}
+		firstErr error
+	)
+	pool.Run(ctx, r.Workers, terminals, func(t Terminal) {
+		tp, err := r.Client.TerminalsService.Params(ctx, current[t.SerialNumber].id, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("terminal %s: %w", t.SerialNumber, err)
+			}
+			return
+		}
+		values := make(map[string]string, len(tp.Rows))
+		for _, p := range tp.Rows {
+			values[p.Tag] = p.Value
+			if p.Value == "" {
+				values[p.Tag] = p.DefaultValue
+			}
+		}
+		params[t.SerialNumber] = values
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return params, firstErr
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/amp360test"
+)
+
+func newServer(t *testing.T) *amp360test.Server {
+	t.Helper()
+	srv := amp360test.NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(amp360test.Data{
+		Templates: []amp360test.Template{{
+			ID:     814,
+			Params: []amp360.Param{{Tag: amp360test.DefaultMIDTag}},
+		}},
+		Terminals: []amp360test.Terminal{
+			{ID: 25, SerialNumber: "8000000002", Name: "old", TemplateID: 814, ModelID: "m1"},
+			{ID: 26, SerialNumber: "8000000003", Name: "stale", TemplateID: 814, ModelID: "m1"},
+		},
+	})
+	return srv
+}
+
+func TestPlanApply(t *testing.T) {
+	srv := newServer(t)
+	s, err := Decode(strings.NewReader(testState + "    params:\n      " + amp360test.DefaultMIDTag + ": \"1\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Reconciler{Client: srv.Client(), Prune: true, Workers: 2}
+
+	p, err := r.Plan(context.Background(), s)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	want := []ActionType{Create, Update, Params, Delete}
+	if len(p.Actions) != len(want) {
+		t.Fatalf("actions got %+v, want %v", p.Actions, want)
+	}
+	for i, a := range p.Actions {
+		if a.Type != want[i] {
+			t.Errorf("action %d got %v, want %v", i, a.Type, want[i])
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := p.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"  + terminal 8000000001\n",
+		`      ~ name: "old" -> "Shop 2"`,
+		`      ~ params.` + amp360test.DefaultMIDTag + `: "" -> "1"`,
+		"  - terminal 8000000003 (id 26)\n",
+		"Plan: 1 to create, 1 to update, 1 to change parameters, 1 to delete.",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("plan does not contain %q:\n%s", s, buf)
+		}
+	}
+
+	results, err := r.Apply(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	for _, res := range results {
+		if res.Status != Applied {
+			t.Errorf("%s %s got %v: %s", res.Action, res.SerialNumber, res.Status, res.Error)
+		}
+	}
+	if sum := Summary(results); sum[Create][Applied] != 1 || sum[Delete][Applied] != 1 {
+		t.Errorf("summary got %v", sum)
+	}
+	if term, _ := srv.Terminal(25); term.Name != "Shop 2" || term.SerialNumber == "" || term.ModelID != "m1" || term.Params[amp360test.DefaultMIDTag] != "1" {
+		t.Errorf("terminal got %+v", term)
+	}
+	if _, ok := srv.Terminal(26); ok {
+		t.Error("pruned terminal still exists")
+	}
+
+	p, err = r.Plan(context.Background(), s)
+	if err != nil || !p.Empty() {
+		t.Errorf("second plan got %+v, error %v", p, err)
+	}
+}
+
+func TestApplySkipsAfterFailure(t *testing.T) {
+	srv := newServer(t)
+	r := &Reconciler{Client: srv.Client()}
+	p := &Plan{Actions: []Action{
+		{Type: Update, SerialNumber: "8000000002", TerminalID: 99, Changes: []Change{{Field: "name", To: "x"}}},
+		{Type: Params, SerialNumber: "8000000002", TerminalID: 99, Changes: []Change{{Field: "params.X", To: "1"}}},
+	}}
+
+	results, err := r.Apply(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if results[0].Status != Failed || results[1].Status != Skipped {
+		t.Errorf("results got %+v", results)
+	}
+}
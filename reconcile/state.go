@@ -0,0 +1,86 @@
+// Package reconcile brings the terminals of a fleet to the state described
+// in a YAML or JSON document.
+//
+//	s, err := reconcile.ReadFile("fleet.yaml")
+//	r := &reconcile.Reconciler{Client: client}
+//	plan, err := r.Plan(ctx, s)
+//	plan.Write(os.Stdout)
+//	results, err := r.Apply(ctx, plan)
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State is the desired state of a fleet.
+//
+//	terminals:
+//	  - serial: "8000044499"
+//	    name: Shop 1
+//	    model: 06c9f7a4-...
+//	    client: ce16c215-...
+//	    template: "814"
+//	    params:
+//	      ACQS._1.ACQINFO.MERCHANTID: "400081203"
+type State struct {
+	Terminals []Terminal `json:"terminals" yaml:"terminals"`
+}
+
+// Terminal is the desired state of a terminal. Empty fields are left as they
+// are on existing terminals.
+type Terminal struct {
+	SerialNumber string            `json:"serial" yaml:"serial"`
+	Name         string            `json:"name,omitempty" yaml:"name,omitempty"`
+	ModelID      string            `json:"model,omitempty" yaml:"model,omitempty"`
+	ClientID     string            `json:"client,omitempty" yaml:"client,omitempty"`
+	TemplateID   string            `json:"template,omitempty" yaml:"template,omitempty"`
+	Params       map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ReadFile reads a desired state document in YAML or JSON.
+func ReadFile(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Decode reads a desired state document in YAML or JSON and validates it.
+func Decode(r io.Reader) (*State, error) {
+	s := &State{}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reconcile: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks that every terminal has a unique serial number.
+func (s *State) Validate() error {
+	seen := map[string]bool{}
+	for i, t := range s.Terminals {
+		switch {
+		case t.SerialNumber == "":
+			return fmt.Errorf("reconcile: terminal %d: missing serial number", i+1)
+		case seen[t.SerialNumber]:
+			return fmt.Errorf("reconcile: terminal %d: duplicate serial number %s", i+1, t.SerialNumber)
+		}
+		seen[t.SerialNumber] = true
+	}
+	return nil
+}
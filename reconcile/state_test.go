@@ -0,0 +1,45 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+)
+
+const testState = `
+terminals:
+  - serial: "8000000001"
+    name: Shop 1
+    model: m1
+    template: "814"
+    params:
+      ACQS._1.ACQINFO.MERCHANTID: "400081203"
+  - serial: "8000000002"
+    name: Shop 2
+`
+
+func TestDecode(t *testing.T) {
+	s, err := Decode(strings.NewReader(testState))
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(s.Terminals) != 2 || s.Terminals[0].Params["ACQS._1.ACQINFO.MERCHANTID"] != "400081203" {
+		t.Errorf("state got %+v", s)
+	}
+
+	s, err = Decode(strings.NewReader(`{"terminals":[{"serial":"8000000001","model":"m1"}]}`))
+	if err != nil || s.Terminals[0].ModelID != "m1" {
+		t.Errorf("JSON state got %+v, error %v", s, err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, doc := range []string{
+		"terminals:\n  - serial: \"1\"\n  - serial: \"1\"\n",
+		"terminals:\n  - name: x\n",
+		"terminals:\n  - serial: \"1\"\n    colour: red\n",
+	} {
+		if _, err := Decode(strings.NewReader(doc)); err == nil {
+			t.Errorf("Error is nil for %q", doc)
+		}
+	}
+}
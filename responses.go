@@ -6,6 +6,12 @@ type Response struct {
 	Data    interface{} `json:"data"`
 }
 
+// BulkResult is the outcome of a bulk parameters update.
+type BulkResult struct {
+	Updated []string `json:"updated"`
+	Failed  []string `json:"failed"`
+}
+
 type BulkResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
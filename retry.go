@@ -0,0 +1,234 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed calls are retried. Attempts are only made
+// for idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) unless RetryPOST is
+// set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every
+	// following attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0..1) of the delay that is randomized.
+	Jitter float64
+	// RetryableStatus lists the HTTP status codes that trigger a retry.
+	RetryableStatus []int
+	// RetryPOST enables retries for POST requests, including bulk uploads.
+	RetryPOST bool
+	// OnRetry, if set, is called before every retry.
+	OnRetry func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt that is about to be retried.
+type RetryAttempt struct {
+	Attempt    int           // number of the failed attempt, starting at 1
+	Method     string        // HTTP method of the request
+	Path       string        // request URL path
+	StatusCode int           // response status, zero on transport errors
+	Err        error         // transport error, nil if a response was received
+	Delay      time.Duration // time to wait before the next attempt
+}
+
+// DefaultRetryPolicy returns a policy retrying transient gateway errors and
+// throttling up to 4 attempts.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.5,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// SetRetryPolicy sets the retry policy of the client. A nil policy disables
+// retries.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.retry = p
+}
+
+func (c *Client) retryPolicy() *RetryPolicy {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.retry
+}
+
+func (p *RetryPolicy) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryPOST
+	}
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	for _, code := range p.RetryableStatus {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt-1)))
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+	if res != nil {
+		if after := retryAfter(res.Header.Get("Retry-After")); after > delay {
+			delay = after
+		}
+	}
+	return delay
+}
+
+// rewindable reports whether the body of req can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// do sends the request built by newReq, retrying it according to the retry
+// policy of the client. newReq is called for every attempt so that request
+// bodies are rebuilt, and gets the current API key. A request rejected with
+// 401 is sent once more if the credentials provider then has another key.
+// It returns the response of the last attempt along with the number of
+// attempts made, and reports the call to the metrics of the client.
+func (c *Client) do(ctx context.Context, newReq func() (*http.Request, error)) (res *http.Response, attempt int, err error) {
+	policy := c.retryPolicy()
+	limiter := c.RateLimiter()
+	logger := c.Logger()
+	metrics := c.Metrics()
+	tracer := c.Tracer()
+	var stats CallStats
+	if metrics != nil {
+		start := time.Now()
+		defer func() {
+			if stats.Method == "" {
+				return
+			}
+			stats.Attempts, stats.Duration, stats.Err = attempt, time.Since(start), err
+			if res != nil {
+				stats.StatusCode = res.StatusCode
+			}
+			metrics.ObserveCall(stats)
+		}()
+	}
+	refreshed := false
+	for attempt = 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, attempt, err
+			}
+		}
+
+		req, err := newReq()
+		if err != nil {
+			return nil, attempt, err
+		}
+		if metrics != nil && stats.Method == "" {
+			stats.Operation, stats.Method = c.operation(ctx, req.Method, req.URL), req.Method
+		}
+		if err := c.authorize(req); err != nil {
+			// Do would have closed the body; closing it stops the writer of a bulk body
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, attempt, err
+		}
+
+		logger.Debugf("amp360: %s %s attempt %d", req.Method, req.URL.Path, attempt)
+		span := startAttempt(ctx, tracer, req, attempt)
+		start := time.Now()
+		res, err := c.client.Do(req)
+		endAttempt(span, res, err)
+		if err != nil {
+			logger.Errorf("amp360: %s %s: %v (%v)", req.Method, req.URL.Path, err, time.Since(start))
+		} else {
+			logger.Debugf("amp360: %s %s: %s (%v)", req.Method, req.URL.Path, res.Status, time.Since(start))
+		}
+		if limiter != nil && res != nil {
+			limiter.update(res)
+		}
+		if !refreshed && c.refreshKey(ctx, req, res) {
+			refreshed = true
+			logger.Warnf("amp360: %s %s: API key rejected, retrying with a new one", req.Method, req.URL.Path)
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			continue
+		}
+		if policy == nil || attempt >= policy.MaxAttempts || !rewindable(req) ||
+			!policy.allowsMethod(req.Method) || !policy.shouldRetry(ctx, res, err) {
+			return res, attempt, err
+		}
+
+		info := RetryAttempt{
+			Attempt: attempt,
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			Err:     err,
+			Delay:   policy.backoff(attempt, res),
+		}
+		if res != nil {
+			info.StatusCode = res.StatusCode
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		logger.Warnf("amp360: retrying %s %s after attempt %d in %v", info.Method, info.Path, attempt, info.Delay)
+		if policy.OnRetry != nil {
+			policy.OnRetry(info)
+		}
+
+		timer := time.NewTimer(info.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func TestRetryGetMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `{"success":false,"message":"bad gateway"}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"count":1,"rows":[{"id":"test1"}]}}`)
+	})
+
+	attempts := []RetryAttempt{}
+	p := testRetryPolicy()
+	p.OnRetry = func(a RetryAttempt) {
+		attempts = append(attempts, a)
+	}
+	c.SetRetryPolicy(p)
+
+	ml := ModelsList{}
+	err := c.ModelsService.GetList(context.Background(), &ml)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls got %v, want %v", calls, 3)
+	}
+	if len(attempts) != 2 || attempts[0].StatusCode != http.StatusBadGateway {
+		t.Errorf("OnRetry attempts got %+v", attempts)
+	}
+	if ml.Count != 1 {
+		t.Errorf("Models count = %v, want %v", ml.Count, 1)
+	}
+}
+
+func TestRetryExhaustedMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"success":false,"message":"unavailable"}`)
+	})
+	c.SetRetryPolicy(testRetryPolicy())
+
+	err := c.ModelsService.GetList(context.Background(), &ModelsList{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error got %v, want *APIError", err)
+	}
+	if apiErr.Attempts != 4 || calls != 4 {
+		t.Errorf("attempts got %v (calls %v), want %v", apiErr.Attempts, calls, 4)
+	}
+}
+
+func TestRetryPOSTMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/terminals/params/bulk/814", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.PostFormValue("param1") != "value1" {
+			t.Errorf("incorrect form value on attempt %d got %v", calls, r.PostFormValue("param1"))
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","failed":[],"updated":["param1"]}`)
+	})
+
+	p := testRetryPolicy()
+	c.SetRetryPolicy(p)
+
+	updated := []string{}
+	failed := []string{}
+	params := map[string]string{"param1": "value1"}
+	files := map[string]string{"TODO": "./TODO"}
+	err := c.TerminalsService.UpdateParams(context.Background(), 814, params, files, &updated, &failed)
+	if err == nil || calls != 1 {
+		t.Fatalf("POST retried without opt-in: err %v, calls %v", err, calls)
+	}
+
+	p.RetryPOST = true
+	calls = 0
+	err = c.TerminalsService.UpdateParams(context.Background(), 814, params, files, &updated, &failed)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls got %v, want %v", calls, 2)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter("3"); got != 3*time.Second {
+		t.Errorf("retryAfter got %v, want %v", got, 3*time.Second)
+	}
+	if got := retryAfter(""); got != 0 {
+		t.Errorf("retryAfter got %v, want 0", got)
+	}
+
+	p := &RetryPolicy{BaseDelay: time.Millisecond}
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+	if got := p.backoff(1, res); got != time.Second {
+		t.Errorf("backoff got %v, want %v", got, time.Second)
+	}
+}
+
+func TestRetryContextCanceledMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c.SetRetryPolicy(testRetryPolicy())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.ModelsService.GetList(ctx, &ModelsList{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Error got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// LoggingRoundTripper prints every request and response passing through
+// Wrapped to stdout. Use NewLoggingRoundTripper to log them, with their
+// headers, to a Logger.
 type LoggingRoundTripper struct {
 	Wrapped http.RoundTripper
 }
@@ -23,3 +26,29 @@ func (l LoggingRoundTripper) RoundTrip(req *http.Request) (res *http.Response, e
 
 	return res, err
 }
+
+// NewLoggingRoundTripper returns a RoundTripper logging every request and
+// response passing through wrapped to logger.
+func NewLoggingRoundTripper(wrapped http.RoundTripper, logger Logger) http.RoundTripper {
+	return loggingRoundTripper{wrapped, logger}
+}
+
+type loggingRoundTripper struct {
+	wrapped http.RoundTripper
+	logger  Logger
+}
+
+func (l loggingRoundTripper) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	l.logger.Infof("Request: | %v | %s |", req.Method, req.URL.String())
+	l.logger.Debugf("Headers: | %s |", redactHeaders(req.Header))
+
+	start := time.Now()
+	res, err = l.wrapped.RoundTrip(req)
+	if err != nil {
+		l.logger.Errorf("Error: %v", err)
+	} else {
+		l.logger.Infof("Response: | %v | %v |", res.Status, time.Since(start))
+	}
+
+	return res, err
+}
@@ -41,7 +41,20 @@ type AppTemplate struct {
 	FileName  string    `json:"fileName,omitempty"`
 }
 
+type TemplatesOpt struct {
+	Size int `url:"size,omitempty"`
+	Page int `url:"page,omitempty"`
+}
+
+// List returns the templates of the client.
+func (c *TemplatesService) List(ctx context.Context, opt *TemplatesOpt) (*TemplateList, error) {
+	ctx = withOperation(ctx, OpTemplatesList, "TemplatesService.List")
+	return fetchList[TemplateList](ctx, c.client, "templates", opt)
+}
+
+// Deprecated: use List.
 func (c *TemplatesService) GetList(ctx context.Context, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpTemplatesList, "TemplatesService.GetList")
 	path := "templates"
 	var url *url.URL
 	if url, err = addOptions(path, opt); err != nil {
@@ -42,7 +42,18 @@ type ParamsOpt struct {
 	CategoryId string `url:"categoryId"`
 }
 
+// Params returns the parameters of template id.
+func (c *TemplatesService) Params(ctx context.Context, id int, opt *ParamsOpt) (*TemplateParams, error) {
+	ctx = withOperation(ctx, OpTemplatesParamsGet, "TemplatesService.Params", IntAttr(AttrTemplateID, id))
+	if id == 0 {
+		return nil, errors.New("required templateID is missing")
+	}
+	return fetchList[TemplateParams](ctx, c.client, fmt.Sprintf("templates/params/%d", id), opt)
+}
+
+// Deprecated: use Params.
 func (c *TemplatesService) GetParams(ctx context.Context, templateID string, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpTemplatesParamsGet, "TemplatesService.GetParams", StringAttr(AttrTemplateID, templateID))
 	if templateID == "" {
 		return errors.New("required templateID is missing")
 	}
@@ -55,11 +66,38 @@ func (c *TemplatesService) GetParams(ctx context.Context, templateID string, opt
 	return c.client.processRequest(ctx, http.MethodGet, *url, nil, v)
 }
 
+// SetParams updates the parameters of template id. paramfiles maps file
+// parameters to the paths of the files to upload.
+func (c *TemplatesService) SetParams(ctx context.Context, id int, params map[string]string, paramfiles map[string]string) (*BulkResult, error) {
+	ctx = withOperation(ctx, OpTemplatesParamsBulk, "TemplatesService.SetParams", IntAttr(AttrTemplateID, id))
+	if id == 0 {
+		return nil, errors.New("required templateID is missing")
+	}
+	url := url.URL{Path: fmt.Sprintf("templates/params/%d", id)}
+	return bulk(ctx, c.client, url, &ParamsUpload{Params: params, Files: pathFiles(paramfiles)})
+}
+
+// UploadParams updates the parameters of template id, streaming the files of
+// up to the server.
+func (c *TemplatesService) UploadParams(ctx context.Context, id int, up *ParamsUpload) (*BulkResult, error) {
+	ctx = withOperation(ctx, OpTemplatesParamsBulk, "TemplatesService.UploadParams", IntAttr(AttrTemplateID, id))
+	if id == 0 {
+		return nil, errors.New("required templateID is missing")
+	}
+	if up == nil {
+		return nil, errors.New("can't update parameters on nil data")
+	}
+	url := url.URL{Path: fmt.Sprintf("templates/params/%d", id)}
+	return bulk(ctx, c.client, url, up)
+}
+
+// Deprecated: use SetParams.
 func (c *TemplatesService) UpdateParams(ctx context.Context, templateID string, params map[string]string, paramfiles map[string]string, u, f interface{}) (err error) {
+	ctx = withOperation(ctx, OpTemplatesParamsBulk, "TemplatesService.UpdateParams", StringAttr(AttrTemplateID, templateID))
 	if templateID == "" {
 		return errors.New("required templateID is missing")
 	}
 	path := fmt.Sprintf("templates/params/%s", templateID)
 	url := url.URL{Path: path}
-	return c.client.processBulkRequest(ctx, http.MethodPost, url, params, paramfiles, u, f)
+	return c.client.processBulkRequest(ctx, http.MethodPost, url, &ParamsUpload{Params: params, Files: pathFiles(paramfiles)}, u, f)
 }
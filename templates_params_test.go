@@ -147,3 +147,46 @@ func TestTemplatesUpdateParamsMock(t *testing.T) {
 		t.Errorf("failed is incorrect got %v, want \"string\"", failed[0])
 	}
 }
+
+func TestTemplatesParamsMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/templates/params/814", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if got := r.URL.Query().Get("categoryId"); got != "value1" {
+			t.Errorf("invalid query received %v, want %v", got, "value1")
+		}
+		fmt.Fprint(w, `{"success":true,"message":"Successfully found the template parameters.","data":{"categories":[{"name":"AMP Cloud","id":"6ba90b8c"}],"count":1,"rows":[{"id":950024,"type":"STRING","tag":"CLOUD.AUTHCODE","name":"CLOUD.AUTHCODE","value":"testtoken","defaultValue":"testtoken","categoryName":"AMP Cloud"}]}}`)
+	})
+
+	if _, err := c.TemplatesService.Params(context.Background(), 0, nil); err == nil {
+		t.Error("Error is nil for missing templateID")
+	}
+
+	tp, err := c.TemplatesService.Params(context.Background(), 814, &ParamsOpt{CategoryId: "value1"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tp.Count != 1 || tp.Rows[0].Value != "testtoken" {
+		t.Errorf("Template params got %+v", tp)
+	}
+}
+
+func TestTemplatesGetParamsRawMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/templates/params/tpl-a", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, `{"success":true,"message":"Successfully found the template parameters.","data":{"count":0,"rows":[],"extra":"kept"}}`)
+	})
+
+	v := map[string]interface{}{}
+	if err := c.TemplatesService.GetParams(context.Background(), "tpl-a", nil, &v); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if v["extra"] != "kept" {
+		t.Errorf("result got %v, want the extra field", v)
+	}
+}
@@ -2,6 +2,7 @@ package amp360
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -55,7 +56,7 @@ func TestGetListMock_Unauthorizaed(t *testing.T) {
 	if err == nil {
 		t.Errorf("Error is = nil, want %v", want)
 	}
-	if err != ErrIvalidToken {
+	if !errors.Is(err, ErrIvalidToken) {
 		t.Errorf("Error got %v, want %v", err, want)
 	}
 }
@@ -61,7 +61,15 @@ type Details struct {
 	} `json:"terminal"`
 }
 
+// Details returns the details of the terminal matching opt.
+func (c *TerminalsService) Details(ctx context.Context, opt *TerminalsOpt) (*Details, error) {
+	ctx = withOperation(ctx, OpTerminalsDetails, "TerminalsService.Details", terminalAttrs(opt)...)
+	return fetchList[Details](ctx, c.client, "terminals/details", opt)
+}
+
+// Deprecated: use Details.
 func (c *TerminalsService) GetDetails(ctx context.Context, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpTerminalsDetails, "TerminalsService.GetDetails", terminalAttrs(opt)...)
 	path := "terminals/details"
 	var url *url.URL
 	if url, err = addOptions(path, opt); err != nil {
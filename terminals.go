@@ -61,6 +61,16 @@ type NewTerminal struct {
 	Parameters    map[string]interface{} `json:"parameters"`
 }
 
+// TerminalUpdate holds the fields to change with Edit. Empty fields are
+// left out of the request, so the server keeps their current values.
+type TerminalUpdate struct {
+	ModelID      string `json:"modelId,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+	Name         string `json:"name,omitempty"`
+	ClientID     string `json:"clientId,omitempty"`
+	TemplateID   string `json:"templateId,omitempty"`
+}
+
 type CreatedTerminal struct {
 	ID              int       `json:"id"`
 	AppTemplateID   string    `json:"AppTemplateId"`
@@ -75,7 +85,15 @@ type CreatedTerminal struct {
 	UpdatedAt       time.Time `json:"updatedAt"`
 }
 
+// List returns the terminals matching opt.
+func (c *TerminalsService) List(ctx context.Context, opt *TerminalsOpt) (*TerminalsList, error) {
+	ctx = withOperation(ctx, OpTerminalsList, "TerminalsService.List")
+	return fetchList[TerminalsList](ctx, c.client, "terminals", opt)
+}
+
+// Deprecated: use List.
 func (c *TerminalsService) GetList(ctx context.Context, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpTerminalsList, "TerminalsService.GetList")
 	path := "terminals"
 	var url *url.URL
 	if url, err = addOptions(path, opt); err != nil {
@@ -85,17 +103,31 @@ func (c *TerminalsService) GetList(ctx context.Context, opt interface{}, v inter
 	return c.client.processRequest(ctx, http.MethodGet, *url, nil, v)
 }
 
+// Add creates a terminal and returns it. It is the typed form of Create,
+// whose name is kept by the deprecated method.
+func (c *TerminalsService) Add(ctx context.Context, data *NewTerminal) (*CreatedTerminal, error) {
+	if data == nil {
+		return nil, errors.New("can't create terminals on nil data")
+	}
+	ctx = withOperation(ctx, OpTerminalsCreate, "TerminalsService.Add", StringAttr(AttrSerialNumber, data.SerialNumber))
+	rel := url.URL{Path: "terminals"}
+	return fetch[CreatedTerminal](ctx, c.client, http.MethodPost, rel, data)
+}
+
+// Deprecated: use Add.
 func (c *TerminalsService) Create(ctx context.Context, data *NewTerminal, v interface{}) (err error) {
 	path := "terminals"
 	rel := url.URL{Path: path}
 	if data == nil {
 		return errors.New("can't create terminals on nil data")
 	}
+	ctx = withOperation(ctx, OpTerminalsCreate, "TerminalsService.Create", StringAttr(AttrSerialNumber, data.SerialNumber))
 
 	return c.client.processRequest(ctx, http.MethodPost, rel, data, v)
 }
 
 func (c *TerminalsService) Update(ctx context.Context, id int, data *NewTerminal) (err error) {
+	ctx = withOperation(ctx, OpTerminalsUpdate, "TerminalsService.Update", IntAttr(AttrTerminalID, id))
 	if id == 0 {
 		return errors.New("required terminalID is missing")
 	}
@@ -108,7 +140,22 @@ func (c *TerminalsService) Update(ctx context.Context, id int, data *NewTerminal
 	return c.client.processRequest(ctx, http.MethodPut, url, data, nil)
 }
 
+// Edit changes only the fields set in data, unlike Update which sends every
+// field of NewTerminal and so blanks those left empty.
+func (c *TerminalsService) Edit(ctx context.Context, id int, data *TerminalUpdate) (err error) {
+	ctx = withOperation(ctx, OpTerminalsUpdate, "TerminalsService.Edit", IntAttr(AttrTerminalID, id))
+	if id == 0 {
+		return errors.New("required terminalID is missing")
+	}
+	if data == nil {
+		return errors.New("can't update terminal on nil data")
+	}
+	url := url.URL{Path: fmt.Sprintf("terminals/%d", id)}
+	return c.client.processRequest(ctx, http.MethodPut, url, data, nil)
+}
+
 func (c *TerminalsService) Delete(ctx context.Context, id int) (err error) {
+	ctx = withOperation(ctx, OpTerminalsDelete, "TerminalsService.Delete", IntAttr(AttrTerminalID, id))
 	if id == 0 {
 		return errors.New("required terminalID is missing")
 	}
@@ -30,7 +30,18 @@ type TerminalParams struct {
 	Rows       []Parameter  `json:"rows"`
 }
 
+// Params returns the parameters of terminal id.
+func (c *TerminalsService) Params(ctx context.Context, id int, opt *ParamsOpt) (*TerminalParams, error) {
+	ctx = withOperation(ctx, OpTerminalsParamsGet, "TerminalsService.Params", IntAttr(AttrTerminalID, id))
+	if id == 0 {
+		return nil, errors.New("required terminalID is missing")
+	}
+	return fetchList[TerminalParams](ctx, c.client, fmt.Sprintf("terminals/params/%d", id), opt)
+}
+
+// Deprecated: use Params.
 func (c *TerminalsService) GetParams(ctx context.Context, id int, opt interface{}, v interface{}) (err error) {
+	ctx = withOperation(ctx, OpTerminalsParamsGet, "TerminalsService.GetParams", IntAttr(AttrTerminalID, id))
 	if id == 0 {
 		return errors.New("required terminalID is missing")
 	}
@@ -44,11 +55,38 @@ func (c *TerminalsService) GetParams(ctx context.Context, id int, opt interface{
 	return c.client.processRequest(ctx, http.MethodGet, *url, nil, v)
 }
 
+// SetParams updates the parameters of terminal id. paramfiles maps file
+// parameters to the paths of the files to upload.
+func (c *TerminalsService) SetParams(ctx context.Context, id int, params map[string]string, paramfiles map[string]string) (*BulkResult, error) {
+	ctx = withOperation(ctx, OpTerminalsParamsBulk, "TerminalsService.SetParams", IntAttr(AttrTerminalID, id))
+	if id == 0 {
+		return nil, errors.New("required terminalID is missing")
+	}
+	url := url.URL{Path: fmt.Sprintf("terminals/params/bulk/%d", id)}
+	return bulk(ctx, c.client, url, &ParamsUpload{Params: params, Files: pathFiles(paramfiles)})
+}
+
+// UploadParams updates the parameters of terminal id, streaming the files of
+// up to the server.
+func (c *TerminalsService) UploadParams(ctx context.Context, id int, up *ParamsUpload) (*BulkResult, error) {
+	ctx = withOperation(ctx, OpTerminalsParamsBulk, "TerminalsService.UploadParams", IntAttr(AttrTerminalID, id))
+	if id == 0 {
+		return nil, errors.New("required terminalID is missing")
+	}
+	if up == nil {
+		return nil, errors.New("can't update parameters on nil data")
+	}
+	url := url.URL{Path: fmt.Sprintf("terminals/params/bulk/%d", id)}
+	return bulk(ctx, c.client, url, up)
+}
+
+// Deprecated: use SetParams.
 func (c *TerminalsService) UpdateParams(ctx context.Context, id int, params map[string]string, paramfiles map[string]string, u, f interface{}) (err error) {
+	ctx = withOperation(ctx, OpTerminalsParamsBulk, "TerminalsService.UpdateParams", IntAttr(AttrTerminalID, id))
 	if id == 0 {
 		return errors.New("required terminalID is missing")
 	}
 	path := fmt.Sprintf("terminals/params/bulk/%d", id)
 	url := url.URL{Path: path}
-	return c.client.processBulkRequest(ctx, http.MethodPost, url, params, paramfiles, u, f)
+	return c.client.processBulkRequest(ctx, http.MethodPost, url, &ParamsUpload{Params: params, Files: pathFiles(paramfiles)}, u, f)
 }
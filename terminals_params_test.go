@@ -148,3 +148,27 @@ func TestTerminalsUpdateParamsMock(t *testing.T) {
 		t.Errorf("failed is incorrect got %v, want \"string\"", failed[0])
 	}
 }
+
+func TestTerminalsSetParamsMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals/params/bulk/814", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		if r.PostFormValue("param1") != "value1" {
+			t.Errorf("incorrect form value got %v, want %v", r.PostFormValue("param1"), "value1")
+		}
+		fmt.Fprint(w, `{"success":true,"message":"Successfully updated 1 parameter(s).","failed":["param2"],"updated":["param1"]}`)
+	})
+
+	res, err := c.TerminalsService.SetParams(context.Background(), 814, map[string]string{"param1": "value1"}, nil)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(res.Updated) != 1 || res.Updated[0] != "param1" {
+		t.Errorf("updated got %v, want [param1]", res.Updated)
+	}
+	if len(res.Failed) != 1 || res.Failed[0] != "param2" {
+		t.Errorf("failed got %v, want [param2]", res.Failed)
+	}
+}
@@ -428,3 +428,46 @@ func BenchmarkGetListMock(b *testing.B) {
 		}
 	}
 }
+
+func TestListMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if got := r.URL.Query().Get("serialNumber"); got != "8000044499" {
+			t.Errorf("serialNumber query got %v, want %v", got, "8000044499")
+		}
+		fmt.Fprint(w, `{"success":true,"message":"Successfully found the terminals.","data":{"count":1,"rows":[{"id":25,"serialNumber":"8000044499","status":"Pending download","name":"Test Terminal 9","AppTemplateId":814,"ClientId":"test_client"}]}}`)
+	})
+
+	tl, err := c.TerminalsService.List(context.Background(), &TerminalsOpt{SerialNumber: "8000044499"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if tl.Count != 1 || tl.Rows[0].ID != 25 {
+		t.Errorf("Terminals got %+v", tl)
+	}
+}
+
+func TestTerminalsAddMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		fmt.Fprint(w, `{"success":true,"message":"Successfully created the terminal.","data":{"id":25,"serialNumber":"8000044499","status":"Pending download","name":"Test Terminal 9"}}`)
+	})
+
+	if _, err := c.TerminalsService.Add(context.Background(), nil); err == nil {
+		t.Error("Error is nil for nil data")
+	}
+
+	ct, err := c.TerminalsService.Add(context.Background(), &NewTerminal{SerialNumber: "8000044499"})
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if ct.ID != 25 || ct.Status != "Pending download" {
+		t.Errorf("Created terminal got %+v", ct)
+	}
+}
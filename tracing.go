@@ -0,0 +1,162 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Tracer opens the spans of the API calls: one per operation, named after
+// the method called, e.g. "TerminalsService.Update", with a child span per
+// HTTP attempt. It must be safe for concurrent use. See the amp360otel
+// package for an OpenTelemetry implementation.
+type Tracer interface {
+	// Start opens a span of the given kind, child of the span of ctx if
+	// any, and returns a context carrying it.
+	Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, Span)
+	// Inject writes the trace context of ctx to the headers of a request.
+	Inject(ctx context.Context, h http.Header)
+}
+
+// SpanKind is the kind of a span.
+type SpanKind int
+
+const (
+	// SpanKindInternal is the kind of the spans of the operations.
+	SpanKindInternal SpanKind = iota
+	// SpanKindClient is the kind of the spans of the HTTP attempts.
+	SpanKindClient
+)
+
+// Span is a span opened by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// SetError marks the span as failed.
+	SetError(err error)
+	End()
+}
+
+// Attribute is a key and a value, a string, an int or a bool, describing a
+// span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func StringAttr(key, value string) Attribute    { return Attribute{Key: key, Value: value} }
+func IntAttr(key string, value int) Attribute   { return Attribute{Key: key, Value: value} }
+func BoolAttr(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Keys of the attributes of the spans.
+const (
+	AttrOperation    = "amp360.operation"
+	AttrTerminalID   = "amp360.terminal.id"
+	AttrSerialNumber = "amp360.terminal.serial_number"
+	AttrTemplateID   = "amp360.template.id"
+	AttrAttempts     = "amp360.attempts"
+	AttrCacheHit     = "amp360.cache.hit"
+	AttrBulkUpdated  = "amp360.bulk.updated"
+	AttrBulkFailed   = "amp360.bulk.failed"
+	AttrHTTPMethod   = "http.request.method"
+	AttrHTTPStatus   = "http.response.status_code"
+	AttrResendCount  = "http.request.resend_count"
+	AttrURLPath      = "url.path"
+)
+
+// SetTracer makes the client trace its calls with t. A nil t disables
+// tracing.
+func (c *Client) SetTracer(t Tracer) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.tracer = t
+}
+
+// Tracer returns the tracer of the client, nil if none was set.
+func (c *Client) Tracer() Tracer {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	return c.tracer
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) SetError(error)             {}
+func (noopSpan) End()                       {}
+
+// startOperation opens the span of a call to u with method, named after the
+// operation of ctx.
+func (c *Client) startOperation(ctx context.Context, method string, u *url.URL) (context.Context, Span) {
+	t := c.Tracer()
+	if t == nil {
+		return ctx, noopSpan{}
+	}
+	name := c.operation(ctx, method, u)
+	span := name
+	attrs := []Attribute{StringAttr(AttrOperation, name), StringAttr(AttrHTTPMethod, method)}
+	if op, ok := ctx.Value(operationKey{}).(*operation); ok {
+		span = op.span
+		attrs = append(attrs, op.attrs...)
+	}
+	return t.Start(ctx, span, SpanKindInternal, attrs...)
+}
+
+// traceResponse records the outcome of the attempts of a call on its span.
+func traceResponse(span Span, res *http.Response, attempts int) {
+	span.SetAttributes(IntAttr(AttrAttempts, attempts))
+	if res != nil {
+		span.SetAttributes(IntAttr(AttrHTTPStatus, res.StatusCode))
+	}
+}
+
+// endSpan ends span, marking it failed if err is not nil.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.SetError(err)
+	}
+	span.End()
+}
+
+// startAttempt opens the span of an attempt to send req and injects its
+// trace context in the headers of req.
+func startAttempt(ctx context.Context, t Tracer, req *http.Request, attempt int) Span {
+	if t == nil {
+		return noopSpan{}
+	}
+	ctx, span := t.Start(ctx, req.Method, SpanKindClient,
+		StringAttr(AttrHTTPMethod, req.Method),
+		StringAttr(AttrURLPath, req.URL.Path),
+		IntAttr(AttrResendCount, attempt-1),
+	)
+	t.Inject(ctx, req.Header)
+	return span
+}
+
+// endAttempt ends the span of an attempt that got res or err.
+func endAttempt(span Span, res *http.Response, err error) {
+	if res != nil {
+		span.SetAttributes(IntAttr(AttrHTTPStatus, res.StatusCode))
+		if res.StatusCode >= 400 {
+			err = fmt.Errorf("%s", res.Status)
+		}
+	}
+	endSpan(span, err)
+}
+
+// terminalAttrs returns the attributes of the terminal looked up with opt,
+// a *TerminalsOpt.
+func terminalAttrs(opt interface{}) []Attribute {
+	o, ok := opt.(*TerminalsOpt)
+	if !ok || o == nil {
+		return nil
+	}
+	var attrs []Attribute
+	if o.ID != 0 {
+		attrs = append(attrs, IntAttr(AttrTerminalID, o.ID))
+	}
+	if o.SerialNumber != "" {
+		attrs = append(attrs, StringAttr(AttrSerialNumber, o.SerialNumber))
+	}
+	return attrs
+}
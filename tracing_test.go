@@ -0,0 +1,166 @@
+package amp360
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	id     int
+	parent int
+	name   string
+	kind   SpanKind
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *fakeSpan) SetError(err error) { s.err = err }
+func (s *fakeSpan) End()               { s.ended = true }
+
+type fakeSpanKey struct{}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, Span) {
+	t.mu.Lock()
+	s := &fakeSpan{tracer: t, id: len(t.spans) + 1, name: name, kind: kind, attrs: map[string]interface{}{}}
+	if p, ok := ctx.Value(fakeSpanKey{}).(*fakeSpan); ok {
+		s.parent = p.id
+	}
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	s.SetAttributes(attrs...)
+	return context.WithValue(ctx, fakeSpanKey{}, s), s
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, h http.Header) {
+	if s, ok := ctx.Value(fakeSpanKey{}).(*fakeSpan); ok {
+		h.Set("X-Span", strconv.Itoa(s.id))
+	}
+}
+
+func TestTracing(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls int
+	var spanHeaders []string
+	mux.HandleFunc("/terminals/params/bulk/25", func(w http.ResponseWriter, r *http.Request) {
+		spanHeaders = append(spanHeaders, r.Header.Get("X-Span"))
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"updated":["a","b"],"failed":["c"]}`)
+	})
+
+	tr := &fakeTracer{}
+	c.SetTracer(tr)
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatus: []int{503}, RetryPOST: true})
+
+	if _, err := c.TerminalsService.SetParams(context.Background(), 25, map[string]string{"a": "1"}, nil); err != nil {
+		t.Fatalf("SetParams returned error: %v", err)
+	}
+
+	if len(tr.spans) != 3 {
+		t.Fatalf("opened %d spans, want 3", len(tr.spans))
+	}
+	op, first, second := tr.spans[0], tr.spans[1], tr.spans[2]
+	if op.name != "TerminalsService.SetParams" || op.kind != SpanKindInternal || op.parent != 0 || !op.ended || op.err != nil {
+		t.Errorf("operation span = %+v", op)
+	}
+	for k, want := range map[string]interface{}{
+		AttrOperation:   OpTerminalsParamsBulk,
+		AttrTerminalID:  25,
+		AttrAttempts:    2,
+		AttrHTTPStatus:  200,
+		AttrBulkUpdated: 2,
+		AttrBulkFailed:  1,
+	} {
+		if op.attrs[k] != want {
+			t.Errorf("operation span %s = %v, want %v", k, op.attrs[k], want)
+		}
+	}
+
+	if first.parent != op.id || second.parent != op.id || first.name != "POST" || first.kind != SpanKindClient {
+		t.Errorf("attempt spans = %+v, %+v", first, second)
+	}
+	if first.err == nil || first.attrs[AttrHTTPStatus] != 503 || first.attrs[AttrResendCount] != 0 {
+		t.Errorf("first attempt span = %+v", first)
+	}
+	if second.err != nil || second.attrs[AttrResendCount] != 1 || !second.ended {
+		t.Errorf("second attempt span = %+v", second)
+	}
+	if len(spanHeaders) != 2 || spanHeaders[0] != "2" || spanHeaders[1] != "3" {
+		t.Errorf("propagated spans = %v, want [2 3]", spanHeaders)
+	}
+}
+
+func TestTracingErrors(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/terminals/details", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success":false,"message":"Terminal not found."}`)
+	})
+
+	tr := &fakeTracer{}
+	c.SetTracer(tr)
+	if _, err := c.TerminalsService.Details(context.Background(), &TerminalsOpt{SerialNumber: "S1"}); err == nil {
+		t.Fatal("Details returned no error")
+	}
+	if len(tr.spans) != 2 {
+		t.Fatalf("opened %d spans, want 2", len(tr.spans))
+	}
+	op := tr.spans[0]
+	if op.name != "TerminalsService.Details" || op.err == nil || op.attrs[AttrSerialNumber] != "S1" {
+		t.Errorf("operation span = %+v", op)
+	}
+}
+
+func TestDoLeavesRequestHeaders(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var spanHeader string
+	mux.HandleFunc("/terminals", func(w http.ResponseWriter, r *http.Request) {
+		spanHeader = r.Header.Get("X-Span")
+		fmt.Fprint(w, `{"success":true}`)
+	})
+
+	c.SetTracer(&fakeTracer{})
+	req, _ := c.NewRequest(http.MethodGet, url.URL{Path: "terminals"}, nil)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	res.Body.Close()
+	if spanHeader == "" {
+		t.Error("trace context was not sent")
+	}
+	for _, h := range []string{"Authorization", "X-Span"} {
+		if v := req.Header.Get(h); v != "" {
+			t.Errorf("Do set %s = %q on the request", h, v)
+		}
+	}
+}
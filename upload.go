@@ -0,0 +1,216 @@
+package amp360
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// ParamFile is a file uploaded as the value of a file parameter. The file is
+// read from Path, from Path within FS when FS is set, or from Reader.
+type ParamFile struct {
+	Param  string    // parameter tag
+	Name   string    // file name sent to the server, defaults to the base of Path
+	Path   string    // path of the file
+	FS     fs.FS     // file system Path is opened in, the OS one if nil
+	Reader io.Reader // file content when Path is empty
+	Size   int64     // size of Reader, zero if unknown
+}
+
+// ProgressFunc reports the number of bytes of a request body sent so far.
+// total is -1 when the size of the body is unknown.
+type ProgressFunc func(sent, total int64)
+
+// ParamsUpload is a bulk parameters update.
+type ParamsUpload struct {
+	Params   map[string]string
+	Files    []ParamFile
+	Progress ProgressFunc
+}
+
+// pathFiles turns a map of parameters to file paths into ParamFiles.
+func pathFiles(paramfiles map[string]string) []ParamFile {
+	files := make([]ParamFile, 0, len(paramfiles))
+	for p, filePath := range paramfiles {
+		files = append(files, ParamFile{Param: p, Path: filePath})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Param < files[j].Param })
+	return files
+}
+
+// replayable reports whether the body of up can be built more than once.
+func (up *ParamsUpload) replayable() bool {
+	for _, f := range up.Files {
+		if f.Path != "" {
+			continue
+		}
+		if _, ok := f.Reader.(io.Seeker); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type openFile struct {
+	ParamFile
+	r    io.Reader
+	size int64 // -1 if unknown
+}
+
+func (f *openFile) close() {
+	if c, ok := f.r.(io.Closer); ok && f.Path != "" {
+		c.Close()
+	}
+}
+
+func (f *ParamFile) open() (*openFile, error) {
+	o := &openFile{ParamFile: *f, size: -1}
+	if o.Path == "" {
+		if o.Reader == nil {
+			return nil, errors.New("param file " + f.Param + " has no content")
+		}
+		if s, ok := o.Reader.(io.Seeker); ok {
+			if _, err := s.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		o.r = o.Reader
+		if o.Size > 0 {
+			o.size = o.Size
+		}
+		return o, nil
+	}
+
+	var file fs.File
+	var err error
+	if o.FS != nil {
+		file, err = o.FS.Open(o.Path)
+		if o.Name == "" {
+			o.Name = path.Base(o.Path)
+		}
+	} else {
+		file, err = os.Open(o.Path)
+		if o.Name == "" {
+			o.Name = filepath.Base(o.Path)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.r = file
+	if st, err := file.Stat(); err == nil && st.Mode().IsRegular() {
+		o.size = st.Size()
+	}
+	return o, nil
+}
+
+// newBulkBody streams the multipart body of a bulk parameters request
+// through a pipe. Files are opened before returning so missing files are
+// reported early. The length is -1 when a file size is unknown.
+func newBulkBody(up *ParamsUpload) (io.ReadCloser, string, int64, error) {
+	files := make([]*openFile, 0, len(up.Files))
+	closeAll := func() {
+		for _, f := range files {
+			f.close()
+		}
+	}
+	for i := range up.Files {
+		f, err := up.Files[i].open()
+		if err != nil {
+			closeAll()
+			return nil, "", 0, err
+		}
+		files = append(files, f)
+	}
+
+	keys := make([]string, 0, len(up.Params))
+	for k := range up.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// the length is the size of the multipart envelope plus the files
+	counter := &countWriter{}
+	envelope := multipart.NewWriter(counter)
+	length := int64(0)
+	for _, f := range files {
+		if _, err := envelope.CreateFormFile(f.Param, f.Name); err != nil {
+			closeAll()
+			return nil, "", 0, err
+		}
+		if f.size < 0 || length < 0 {
+			length = -1
+		} else {
+			length += f.size
+		}
+	}
+	for _, k := range keys {
+		envelope.WriteField(k, up.Params[k])
+	}
+	envelope.Close()
+	if length >= 0 {
+		length += counter.n
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	writer.SetBoundary(envelope.Boundary())
+	go func() {
+		defer closeAll()
+		pw.CloseWithError(writeBulkBody(writer, files, keys, up.Params))
+	}()
+
+	var body io.ReadCloser = pr
+	if up.Progress != nil {
+		body = &progressReader{ReadCloser: pr, total: length, progress: up.Progress}
+	}
+	return body, writer.FormDataContentType(), length, nil
+}
+
+func writeBulkBody(writer *multipart.Writer, files []*openFile, keys []string, params map[string]string) error {
+	for _, f := range files {
+		part, err := writer.CreateFormFile(f.Param, f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.r); err != nil {
+			return err
+		}
+	}
+	for _, k := range keys {
+		if err := writer.WriteField(k, params[k]); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressReader struct {
+	io.ReadCloser
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+	return n, err
+}
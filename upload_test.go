@@ -0,0 +1,184 @@
+package amp360
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestUploadParamsMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var contentLength int64
+	mux.HandleFunc("/terminals/params/bulk/814", func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("error reading request body: %v", err)
+		}
+		if r.ContentLength >= 0 && int64(len(body)) != r.ContentLength {
+			t.Errorf("body length got %v, want %v", len(body), r.ContentLength)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if r.PostFormValue("param1") != "value1" {
+			t.Errorf("incorrect form value got %v, want %v", r.PostFormValue("param1"), "value1")
+		}
+		for _, name := range []string{"EMV", "KEYS"} {
+			f, fh, err := r.FormFile(name)
+			if err != nil {
+				t.Errorf("missing file %s: %v", name, err)
+				continue
+			}
+			b, _ := io.ReadAll(f)
+			if string(b) != name+" content" {
+				t.Errorf("file %s got %q", name, b)
+			}
+			if fh.Filename != strings.ToLower(name)+".bin" {
+				t.Errorf("file name got %v", fh.Filename)
+			}
+		}
+		fmt.Fprint(w, `{"success":true,"message":"ok","failed":[],"updated":["param1","EMV","KEYS"]}`)
+	})
+
+	fsys := fstest.MapFS{"keys/keys.bin": {Data: []byte("KEYS content")}}
+	var sent, total int64
+	up := &ParamsUpload{
+		Params: map[string]string{"param1": "value1"},
+		Files: []ParamFile{
+			{Param: "EMV", Name: "emv.bin", Reader: strings.NewReader("EMV content"), Size: 11},
+			{Param: "KEYS", Path: "keys/keys.bin", FS: fsys},
+		},
+		Progress: func(s, t int64) {
+			sent, total = s, t
+		},
+	}
+	res, err := c.TerminalsService.UploadParams(context.Background(), 814, up)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if len(res.Updated) != 3 {
+		t.Errorf("updated got %v", res.Updated)
+	}
+	if contentLength <= 0 || total != contentLength || sent != total {
+		t.Errorf("progress got %v/%v, content length %v", sent, total, contentLength)
+	}
+
+	// a reader of unknown size is sent chunked
+	up.Files[0] = ParamFile{Param: "EMV", Name: "emv.bin", Reader: io.MultiReader(strings.NewReader("EMV content"))}
+	if _, err := c.TerminalsService.UploadParams(context.Background(), 814, up); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if contentLength != -1 || total != -1 {
+		t.Errorf("content length got %v, progress total %v, want -1", contentLength, total)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+func TestUploadParamsErrorsMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	var calls atomic.Int32
+	mux.HandleFunc("/templates/params/814", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			return
+		}
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.RetryPOST = true
+	c.SetRetryPolicy(p)
+
+	_, err := c.TemplatesService.SetParams(context.Background(), 814, nil, map[string]string{"EMV": "./missing.bin"})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Error got %v, want %v", err, os.ErrNotExist)
+	}
+
+	_, err = c.TemplatesService.UploadParams(context.Background(), 814, &ParamsUpload{
+		Files: []ParamFile{{Param: "EMV", Name: "emv.bin", Reader: failingReader{}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "disk failure") {
+		t.Errorf("Error got %v, want disk failure", err)
+	}
+
+	// a one-shot reader cannot be replayed, so the upload is not retried
+	calls.Store(0)
+	_, err = c.TemplatesService.UploadParams(context.Background(), 814, &ParamsUpload{
+		Files: []ParamFile{{Param: "EMV", Name: "emv.bin", Reader: io.MultiReader(strings.NewReader("EMV"))}},
+	})
+	if err == nil || calls.Load() != 1 {
+		t.Errorf("one-shot upload got error %v after %d calls, want 1 call", err, calls.Load())
+	}
+
+	calls.Store(0)
+	_, err = c.TemplatesService.UploadParams(context.Background(), 814, &ParamsUpload{
+		Files: []ParamFile{{Param: "EMV", Name: "emv.bin", Reader: strings.NewReader("EMV")}},
+	})
+	if err == nil || int(calls.Load()) != p.MaxAttempts {
+		t.Errorf("seekable upload got error %v after %d calls, want %d calls", err, calls.Load(), p.MaxAttempts)
+	}
+}
+
+// closeFS records the files closed.
+type closeFS struct {
+	fstest.MapFS
+	closed chan string
+}
+
+func (f closeFS) Open(name string) (fs.File, error) {
+	file, err := f.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return closeFile{file, name, f.closed}, nil
+}
+
+type closeFile struct {
+	fs.File
+	name   string
+	closed chan string
+}
+
+func (f closeFile) Close() error {
+	f.closed <- f.name
+	return f.File.Close()
+}
+
+func TestUploadParamsCredentialsErrorMock(t *testing.T) {
+	c, _, _, teardown := setup()
+	defer teardown()
+	t.Setenv("TEST_AMP360_KEY", "")
+	c.SetCredentials(EnvCredentials("TEST_AMP360_KEY"))
+
+	fsys := closeFS{fstest.MapFS{"emv.bin": {Data: []byte("EMV")}}, make(chan string, 1)}
+	_, err := c.TemplatesService.UploadParams(context.Background(), 814, &ParamsUpload{
+		Files: []ParamFile{{Param: "EMV", Path: "emv.bin", FS: fsys}},
+	})
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Error got %v, want %v", err, ErrNoCredentials)
+	}
+	select {
+	case <-fsys.closed:
+	case <-time.After(time.Second):
+		t.Error("file not closed after the credentials error")
+	}
+}
@@ -0,0 +1,406 @@
+package amp360
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ParamError is a value rejected by the type or validator of a parameter.
+type ParamError struct {
+	Tag    string
+	Value  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s=%q: %s", e.Tag, e.Value, e.Reason)
+}
+
+// ValidationErrors lists the parameters of an update that failed
+// validation. It matches ErrIncorrect with errors.Is.
+type ValidationErrors []*ParamError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, pe := range e {
+		msgs[i] = pe.Error()
+	}
+	return "invalid parameters: " + strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrIncorrect
+}
+
+// ParamValue is the string value of a parameter with typed accessors.
+type ParamValue string
+
+// Int parses the value as an integer.
+func (v ParamValue) Int() (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+}
+
+// Float parses the value as a number.
+func (v ParamValue) Float() (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+}
+
+// Bool parses the value as a boolean, accepting 1/0, true/false, yes/no
+// and on/off.
+func (v ParamValue) Bool() (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(string(v))) {
+	case "1", "true", "yes", "on", "y":
+		return true, nil
+	case "0", "false", "no", "off", "n", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", string(v))
+}
+
+// HexBytes decodes the value as a hex string.
+func (v ParamValue) HexBytes() ([]byte, error) {
+	return hex.DecodeString(strings.TrimSpace(string(v)))
+}
+
+// Int returns the value of p as an integer.
+func (p Param) Int() (int64, error) { return ParamValue(p.Value).Int() }
+
+// Bool returns the value of p as a boolean.
+func (p Param) Bool() (bool, error) { return ParamValue(p.Value).Bool() }
+
+// HexBytes returns the value of p decoded from hex.
+func (p Param) HexBytes() ([]byte, error) { return ParamValue(p.Value).HexBytes() }
+
+// File returns the path of the file of a FILE parameter.
+func (p Param) File() string { return p.FilePath }
+
+// Validate checks value against the type and validator of p.
+func (p Param) Validate(value string) error {
+	return ValidateParam(p.Tag, p.Type, p.Validator, value)
+}
+
+// Int returns the value of p as an integer.
+func (p Parameter) Int() (int64, error) { return ParamValue(p.Value).Int() }
+
+// Bool returns the value of p as a boolean.
+func (p Parameter) Bool() (bool, error) { return ParamValue(p.Value).Bool() }
+
+// HexBytes returns the value of p decoded from hex.
+func (p Parameter) HexBytes() ([]byte, error) { return ParamValue(p.Value).HexBytes() }
+
+// File returns the path of the file of a FILE parameter.
+func (p Parameter) File() string {
+	if s, ok := p.FilePath.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// Validate checks value against the type and validator of p.
+func (p Parameter) Validate(value string) error {
+	return ValidateParam(p.Tag, p.Type, p.Validator, value)
+}
+
+// ValidateParam checks value against a parameter type and validator. It
+// returns a *ParamError when the value is rejected.
+//
+// Types are matched case-insensitively: INT/INTEGER, NUMBER/NUMERIC/DECIMAL,
+// BOOL/BOOLEAN, HEX and BCD are checked, other types accept any value.
+// A validator is a list of rules separated by ";":
+//
+//	len:N, len:N-M          value length
+//	range:A-B, range:A..B   numeric range
+//	enum:a|b|c              allowed values
+//	hex, bcd, numeric, alpha, alphanumeric
+//	regex:re, /re/, ^re$    regular expression
+//
+// A regular expression runs to the end of the validator, so it may hold ";".
+// Rules of any other form are left to the server.
+func ValidateParam(tag, typ, validator, value string) error {
+	fail := func(format string, args ...interface{}) error {
+		return &ParamError{Tag: tag, Value: value, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch strings.ToUpper(typ) {
+	case "INT", "INTEGER":
+		if _, err := ParamValue(value).Int(); err != nil {
+			return fail("not an integer")
+		}
+	case "NUMBER", "NUMERIC", "DECIMAL", "FLOAT":
+		if _, err := ParamValue(value).Float(); err != nil {
+			return fail("not a number")
+		}
+	case "BOOL", "BOOLEAN":
+		if _, err := ParamValue(value).Bool(); err != nil {
+			return fail("not a boolean")
+		}
+	case "HEX":
+		if !isHex(value) {
+			return fail("not an even-length hex string")
+		}
+	case "BCD":
+		if !isDigits(value) {
+			return fail("not a BCD digit string")
+		}
+	}
+
+	for _, rule := range splitRules(validator) {
+		if reason := checkRule(rule, value); reason != "" {
+			return fail("%s", reason)
+		}
+	}
+	return nil
+}
+
+var (
+	rangeRe        = regexp.MustCompile(`^(-?[0-9.]+)\s*(?:-|\.\.)\s*(-?[0-9.]+)$`)
+	alphaRe        = regexp.MustCompile(`^[A-Za-z]*$`)
+	alphanumericRe = regexp.MustCompile(`^[A-Za-z0-9]*$`)
+)
+
+// splitRules splits validator into its rules. A regular expression takes
+// the rest of the validator.
+func splitRules(validator string) []string {
+	var rules []string
+	for validator != "" {
+		validator = strings.TrimSpace(validator)
+		if isPattern(validator) {
+			return append(rules, validator)
+		}
+		rule, rest, _ := strings.Cut(validator, ";")
+		rules = append(rules, strings.TrimSpace(rule))
+		validator = rest
+	}
+	return rules
+}
+
+// isPattern reports whether rule is a regular expression rule.
+func isPattern(rule string) bool {
+	name, _, _ := strings.Cut(rule, ":")
+	name = strings.ToLower(name)
+	return name == "regex" || name == "regexp" || strings.HasPrefix(rule, "/") || strings.HasPrefix(rule, "^")
+}
+
+// checkRule returns why value breaks rule, or "" if it does not.
+func checkRule(rule, value string) string {
+	if rule == "" {
+		return ""
+	}
+	name, arg, hasArg := strings.Cut(rule, ":")
+	name = strings.ToLower(name)
+
+	switch {
+	case hasArg && (name == "len" || name == "length"):
+		min, max, ok := bounds(arg)
+		n := float64(utf8.RuneCountInString(value))
+		if ok && (n < min || n > max) {
+			if min == max {
+				return fmt.Sprintf("length must be %v", min)
+			}
+			return fmt.Sprintf("length must be between %v and %v", min, max)
+		}
+		return ""
+	case hasArg && name == "range":
+		return checkRange(arg, value)
+	case hasArg && name == "enum":
+		return checkEnum(arg, value)
+	case hasArg && (name == "regex" || name == "regexp"):
+		return checkRegexp(arg, value)
+	}
+
+	switch strings.ToLower(rule) {
+	case "hex":
+		if !isHex(value) {
+			return "must be an even-length hex string"
+		}
+		return ""
+	case "bcd", "numeric", "digits":
+		if !isDigits(value) {
+			return "must contain digits only"
+		}
+		return ""
+	case "alpha":
+		if !alphaRe.MatchString(value) {
+			return "must contain letters only"
+		}
+		return ""
+	case "alphanumeric", "alnum":
+		if !alphanumericRe.MatchString(value) {
+			return "must contain letters and digits only"
+		}
+		return ""
+	}
+
+	switch {
+	case len(rule) > 1 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/"):
+		return checkRegexp(rule[1:len(rule)-1], value)
+	case strings.HasPrefix(rule, "^") && strings.HasSuffix(rule, "$"):
+		return checkRegexp(rule, value)
+	}
+	return ""
+}
+
+func bounds(s string) (float64, float64, bool) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, n, true
+	}
+	m := rangeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	min, err1 := strconv.ParseFloat(m[1], 64)
+	max, err2 := strconv.ParseFloat(m[2], 64)
+	return min, max, err1 == nil && err2 == nil
+}
+
+func checkRange(arg, value string) string {
+	min, max, ok := bounds(arg)
+	if !ok {
+		return ""
+	}
+	n, err := ParamValue(value).Float()
+	if err != nil {
+		return "not a number"
+	}
+	if n < min || n > max {
+		return fmt.Sprintf("must be between %v and %v", min, max)
+	}
+	return ""
+}
+
+func checkEnum(arg, value string) string {
+	allowed := strings.Split(arg, "|")
+	for _, a := range allowed {
+		if strings.TrimSpace(a) == value {
+			return ""
+		}
+	}
+	return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+type pattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// patterns caches the compiled validator patterns by expression.
+var patterns sync.Map
+
+// compilePattern compiles expr to match whole values, once per expression.
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	if p, ok := patterns.Load(expr); ok {
+		return p.(pattern).re, p.(pattern).err
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	patterns.Store(expr, pattern{re, err})
+	return re, err
+}
+
+// checkRegexp checks that the whole of value matches expr. A pattern that
+// doesn't compile is reported rather than ignored.
+func checkRegexp(expr, value string) string {
+	re, err := compilePattern(expr)
+	if err != nil {
+		return fmt.Sprintf("invalid validator pattern %q: %v", expr, err)
+	}
+	if !re.MatchString(value) {
+		return fmt.Sprintf("must match %s", expr)
+	}
+	return ""
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type paramValidator interface {
+	Validate(value string) error
+}
+
+func validateUpdate(schema map[string]paramValidator, params map[string]string, paramfiles map[string]string) error {
+	var errs ValidationErrors
+	for tag, value := range params {
+		p, ok := schema[tag]
+		if !ok {
+			errs = append(errs, &ParamError{Tag: tag, Value: value, Reason: "unknown parameter"})
+			continue
+		}
+		if err := p.Validate(value); err != nil {
+			errs = append(errs, err.(*ParamError))
+		}
+	}
+	for tag, path := range paramfiles {
+		if _, ok := schema[tag]; !ok {
+			errs = append(errs, &ParamError{Tag: tag, Value: path, Reason: "unknown parameter"})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Tag < errs[j].Tag })
+	return errs
+}
+
+// Validate checks an update of the template parameters. It returns
+// ValidationErrors listing every rejected parameter.
+func (tp *TemplateParams) Validate(params map[string]string, paramfiles map[string]string) error {
+	schema := make(map[string]paramValidator, len(tp.Rows))
+	for _, p := range tp.Rows {
+		schema[p.Tag] = p
+	}
+	return validateUpdate(schema, params, paramfiles)
+}
+
+// Validate checks an update of the terminal parameters. It returns
+// ValidationErrors listing every rejected parameter.
+func (tp *TerminalParams) Validate(params map[string]string, paramfiles map[string]string) error {
+	schema := make(map[string]paramValidator, len(tp.Rows))
+	for _, p := range tp.Rows {
+		schema[p.Tag] = p
+	}
+	return validateUpdate(schema, params, paramfiles)
+}
+
+// SetParamsValidated fetches the parameters of terminal id, validates the
+// update against them and only then sends it.
+func (c *TerminalsService) SetParamsValidated(ctx context.Context, id int, params map[string]string, paramfiles map[string]string) (*BulkResult, error) {
+	tp, err := c.Params(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tp.Validate(params, paramfiles); err != nil {
+		return nil, err
+	}
+	return c.SetParams(ctx, id, params, paramfiles)
+}
+
+// SetParamsValidated fetches the parameters of template id, validates the
+// update against them and only then sends it.
+func (c *TemplatesService) SetParamsValidated(ctx context.Context, id int, params map[string]string, paramfiles map[string]string) (*BulkResult, error) {
+	tp, err := c.Params(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tp.Validate(params, paramfiles); err != nil {
+		return nil, err
+	}
+	return c.SetParams(ctx, id, params, paramfiles)
+}
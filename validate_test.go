@@ -0,0 +1,136 @@
+package amp360
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateParam(t *testing.T) {
+	tests := []struct {
+		typ, validator, value string
+		ok                    bool
+	}{
+		{"STRING", "", "anything", true},
+		{"INTEGER", "", "42", true},
+		{"INTEGER", "", "4.2", false},
+		{"NUMBER", "range:1-100", "99.5", true},
+		{"NUMBER", "range:1-100", "101", false},
+		{"INT", "range:0..10", "-1", false},
+		{"INT", "1,2", "5", true},
+		{"INT", "1..2", "5", true},
+		{"BOOLEAN", "", "yes", true},
+		{"BOOLEAN", "", "maybe", false},
+		{"HEX", "", "0A1B", true},
+		{"HEX", "", "0A1", false},
+		{"BCD", "len:4", "1234", true},
+		{"BCD", "", "12A4", false},
+		{"STRING", "len:8", "1234567", false},
+		{"STRING", "len:2-4", "abc", true},
+		{"STRING", "enum:CELLULAR|WIFI|ETHERNET", "WIFI", true},
+		{"STRING", "enum:CELLULAR|WIFI", "GPRS", false},
+		{"STRING", "CELLULAR|WIFI", "GPRS", true},
+		{"STRING", "^[0-9]{8}$", "12345678", true},
+		{"STRING", "/^[0-9]{8}$/", "1234567a", false},
+		{"STRING", "[A-Z]{3}", "abc", true},
+		{"STRING", "regex:^T.*", "TID", true},
+		{"STRING", "regex:^T", "TID", false},
+		{"STRING", "/[0-9]{8}/", "abc123456789xyz", false},
+		{"STRING", "regex:[0-9]{8}", "123456789", false},
+		{"STRING", "regex:[0-9", "1", false},
+		{"STRING", "hex;len:4", "0a0b", true},
+		{"STRING", "regex:a;b", "a;b", true},
+		{"STRING", "len:3; ^a;b$", "a;b", true},
+		{"STRING", "len:3; ^a;b$", "a-b", false},
+		{"STRING", "alphanumeric", "AB-1", false},
+		{"STRING", "something server side", "x", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateParam("TAG", tt.typ, tt.validator, tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateParam(%q, %q, %q) = %v, want ok %v", tt.typ, tt.validator, tt.value, err, tt.ok)
+		}
+		var pe *ParamError
+		if err != nil && !errors.As(err, &pe) {
+			t.Errorf("Error got %T, want *ParamError", err)
+		}
+	}
+}
+
+func TestValidateParam_invalidPattern(t *testing.T) {
+	err := ValidateParam("TAG", "STRING", "regex:[0-9", "1")
+	if err == nil || !strings.Contains(err.Error(), "invalid validator pattern") {
+		t.Errorf("ValidateParam = %v, want an invalid pattern error", err)
+	}
+}
+
+func TestCompilePatternCached(t *testing.T) {
+	re, err := compilePattern("[A-Z]{2}")
+	if err != nil {
+		t.Fatalf("compilePattern returned error: %v", err)
+	}
+	if again, _ := compilePattern("[A-Z]{2}"); again != re {
+		t.Error("pattern was compiled twice")
+	}
+}
+
+func TestParamAccessors(t *testing.T) {
+	p := Param{Value: "0A1B"}
+	if b, err := p.HexBytes(); err != nil || len(b) != 2 || b[0] != 0x0a {
+		t.Errorf("HexBytes got %v, %v", b, err)
+	}
+
+	tp := Parameter{Value: "1", FilePath: "/files/emv.xml"}
+	if v, err := tp.Bool(); err != nil || !v {
+		t.Errorf("Bool got %v, %v", v, err)
+	}
+	if v, err := tp.Int(); err != nil || v != 1 {
+		t.Errorf("Int got %v, %v", v, err)
+	}
+	if tp.File() != "/files/emv.xml" {
+		t.Errorf("File got %v", tp.File())
+	}
+	if (Parameter{}).File() != "" {
+		t.Error("File of a nil path is not empty")
+	}
+}
+
+func TestSetParamsValidatedMock(t *testing.T) {
+	c, mux, _, teardown := setup()
+	defer teardown()
+
+	posted := false
+	mux.HandleFunc("/terminals/params/814", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"message":"ok","data":{"categories":[],"count":2,"rows":[{"id":1,"type":"STRING","tag":"TID","validator":"^[0-9]{8}$","value":"00000000"},{"id":2,"type":"BOOLEAN","tag":"TIP","validator":"","value":"0"}]}}`)
+	})
+	mux.HandleFunc("/terminals/params/bulk/814", func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+		fmt.Fprint(w, `{"success":true,"message":"ok","failed":[],"updated":["TID"]}`)
+	})
+
+	_, err := c.TerminalsService.SetParamsValidated(context.Background(), 814, map[string]string{"TID": "123", "TIP": "maybe", "MID": "1"}, nil)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("Error got %v, want ValidationErrors", err)
+	}
+	if len(verrs) != 3 || verrs[0].Tag != "MID" || verrs[1].Tag != "TID" || verrs[2].Tag != "TIP" {
+		t.Errorf("validation errors got %v", verrs)
+	}
+	if !errors.Is(err, ErrIncorrect) {
+		t.Errorf("Error got %v, want %v", err, ErrIncorrect)
+	}
+	if posted {
+		t.Error("invalid update was sent")
+	}
+
+	if _, err := c.TerminalsService.SetParamsValidated(context.Background(), 814, map[string]string{"TID": "12345678"}, nil); err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if !posted {
+		t.Error("valid update was not sent")
+	}
+}
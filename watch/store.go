@@ -0,0 +1,99 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TerminalState is what a snapshot records of a terminal.
+type TerminalState struct {
+	ID              int       `json:"id"`
+	SerialNumber    string    `json:"serialNumber"`
+	Name            string    `json:"name"`
+	Status          string    `json:"status"`
+	ModelID         string    `json:"modelId"`
+	ClientID        string    `json:"clientId"`
+	TemplateID      int       `json:"templateId"`
+	FirmwareID      string    `json:"firmwareId"`
+	FirmwareVersion string    `json:"firmwareVersion,omitempty"` // only with Watcher.Details
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// Snapshot is the state of the fleet at a point in time.
+type Snapshot struct {
+	Taken     time.Time             `json:"taken"`
+	Terminals map[int]TerminalState `json:"terminals"`
+}
+
+// Store keeps the last snapshot between polls, and restarts.
+type Store interface {
+	// Load returns the last saved snapshot, or nil if there is none.
+	Load(ctx context.Context) (*Snapshot, error)
+	// Save replaces the saved snapshot with s.
+	Save(ctx context.Context, s *Snapshot) error
+}
+
+// MemoryStore is a Store kept in memory.
+type MemoryStore struct {
+	mu   sync.Mutex
+	last *Snapshot
+}
+
+func (m *MemoryStore) Load(ctx context.Context) (*Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last, nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, s *Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = s
+	return nil
+}
+
+// FileStore is a Store keeping the snapshot in a JSON file, replaced
+// atomically on every save.
+type FileStore struct {
+	Path string
+}
+
+func (f *FileStore) Load(ctx context.Context) (*Snapshot, error) {
+	b, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (f *FileStore) Save(ctx context.Context, s *Snapshot) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
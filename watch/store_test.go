@@ -0,0 +1,34 @@
+package watch
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	f := &FileStore{Path: filepath.Join(t.TempDir(), "fleet.json")}
+
+	s, err := f.Load(ctx)
+	if err != nil || s != nil {
+		t.Fatalf("empty store got %v, error %v", s, err)
+	}
+
+	want := &Snapshot{Taken: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), Terminals: map[int]TerminalState{25: {ID: 25, Status: "Active"}}}
+	if err := f.Save(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	s, err = f.Load(ctx)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	if !s.Taken.Equal(want.Taken) || s.Terminals[25].Status != "Active" {
+		t.Errorf("snapshot got %+v", s)
+	}
+
+	if events := Diff(s, &Snapshot{Terminals: map[int]TerminalState{}}); len(events) != 1 || events[0].Type != TerminalDeleted {
+		t.Errorf("events got %v", events)
+	}
+}
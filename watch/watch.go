@@ -0,0 +1,267 @@
+// Package watch polls the terminals of a fleet and reports their changes as
+// events, since the AMP360 API has no notifications.
+//
+//	w := &watch.Watcher{Client: client, Store: &watch.FileStore{Path: "fleet.json"}}
+//	for ev := range w.Events(ctx) {
+//		log.Println(ev)
+//	}
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andrei-cloud/amp360"
+	"github.com/andrei-cloud/amp360/internal/pool"
+)
+
+// EventType is the kind of change of an event.
+type EventType string
+
+const (
+	TerminalCreated EventType = "terminal.created"
+	TerminalDeleted EventType = "terminal.deleted"
+	StatusChanged   EventType = "terminal.status"
+	FirmwareChanged EventType = "terminal.firmware"
+	TemplateChanged EventType = "terminal.template"
+	ClientChanged   EventType = "terminal.client"
+)
+
+// Event is a change of a terminal between two snapshots. From and To are the
+// old and new values of the changed field, empty for creates and deletes.
+type Event struct {
+	Type     EventType     `json:"type"`
+	Terminal TerminalState `json:"terminal"` // last known state
+	From     string        `json:"from,omitempty"`
+	To       string        `json:"to,omitempty"`
+	Time     time.Time     `json:"time"` // when the new snapshot was taken
+}
+
+func (e Event) String() string {
+	if e.From == "" && e.To == "" {
+		return fmt.Sprintf("%s %s (id %d)", e.Type, e.Terminal.SerialNumber, e.Terminal.ID)
+	}
+	return fmt.Sprintf("%s %s (id %d): %q -> %q", e.Type, e.Terminal.SerialNumber, e.Terminal.ID, e.From, e.To)
+}
+
+// Diff returns the events that turn prev into cur, ordered by terminal ID.
+// A nil prev yields no events.
+func Diff(prev, cur *Snapshot) []Event {
+	if prev == nil {
+		return nil
+	}
+	var events []Event
+	for _, id := range sortedIDs(cur.Terminals, prev.Terminals) {
+		old, hadOld := prev.Terminals[id]
+		t, hasNew := cur.Terminals[id]
+		switch {
+		case !hadOld:
+			events = append(events, Event{Type: TerminalCreated, Terminal: t, Time: cur.Taken})
+			continue
+		case !hasNew:
+			events = append(events, Event{Type: TerminalDeleted, Terminal: old, Time: cur.Taken})
+			continue
+		}
+
+		changed := func(typ EventType, from, to string) {
+			if from != to {
+				events = append(events, Event{Type: typ, Terminal: t, From: from, To: to, Time: cur.Taken})
+			}
+		}
+		changed(StatusChanged, old.Status, t.Status)
+		if old.FirmwareVersion != "" && t.FirmwareVersion != "" {
+			changed(FirmwareChanged, old.FirmwareVersion, t.FirmwareVersion)
+		} else {
+			changed(FirmwareChanged, old.FirmwareID, t.FirmwareID)
+		}
+		changed(TemplateChanged, strconv.Itoa(old.TemplateID), strconv.Itoa(t.TemplateID))
+		changed(ClientChanged, old.ClientID, t.ClientID)
+	}
+	return events
+}
+
+func sortedIDs(a, b map[int]TerminalState) []int {
+	ids := make([]int, 0, len(a))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Watcher polls the terminals and emits the changes since the previous
+// poll. The first poll without a stored snapshot only records a baseline.
+type Watcher struct {
+	Client *amp360.Client
+	// Store keeps the last snapshot, a MemoryStore if nil.
+	Store Store
+	// Interval is the time between polls, 5 minutes if zero.
+	Interval time.Duration
+	// Opt narrows the terminals watched.
+	Opt *amp360.TerminalsOpt
+	// Details fetches the details of the new and updated terminals to
+	// record their firmware version.
+	Details bool
+	// Workers is the number of details fetched concurrently, 4 if zero.
+	Workers int
+	// OnEvent, if set, is called for every event.
+	OnEvent func(Event)
+	// OnError, if set, is called when a poll run by Run or Events fails.
+	OnError func(error)
+
+	once sync.Once
+}
+
+func (w *Watcher) init() {
+	w.once.Do(func() {
+		if w.Store == nil {
+			w.Store = &MemoryStore{}
+		}
+	})
+}
+
+// Poll takes a snapshot, saves it and returns the events since the stored
+// one.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	w.init()
+	prev, err := w.Store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watch: load snapshot: %w", err)
+	}
+
+	cur := &Snapshot{Taken: time.Now().UTC(), Terminals: map[int]TerminalState{}}
+	it := w.Client.TerminalsService.All(ctx, w.Opt)
+	for it.Next() {
+		t := it.Value()
+		cur.Terminals[t.ID] = TerminalState{
+			ID:           t.ID,
+			SerialNumber: t.SerialNumber,
+			Name:         t.Name,
+			Status:       t.Status,
+			ModelID:      t.TerminalModelID,
+			ClientID:     t.ClientID,
+			TemplateID:   t.AppTemplateID,
+			FirmwareID:   t.FirmwareID,
+			UpdatedAt:    t.UpdatedAt,
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if w.Details {
+		if err := w.details(ctx, prev, cur); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Store.Save(ctx, cur); err != nil {
+		return nil, fmt.Errorf("watch: save snapshot: %w", err)
+	}
+	events := Diff(prev, cur)
+	if w.OnEvent != nil {
+		for _, ev := range events {
+			w.OnEvent(ev)
+		}
+	}
+	return events, nil
+}
+
+// details fills the firmware versions of cur, fetching the details of the
+// terminals that are new or changed since prev.
+func (w *Watcher) details(ctx context.Context, prev, cur *Snapshot) error {
+	var ids []int
+	for id, t := range cur.Terminals {
+		if prev != nil {
+			if old, ok := prev.Terminals[id]; ok && old.FirmwareVersion != "" && old.UpdatedAt.Equal(t.UpdatedAt) && old.FirmwareID == t.FirmwareID {
+				t.FirmwareVersion = old.FirmwareVersion
+				cur.Terminals[id] = t
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	pool.Run(ctx, w.Workers, ids, func(id int) {
+		d, err := w.Client.TerminalsService.Details(ctx, &amp360.TerminalsOpt{ID: id})
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("watch: terminal %d: %w", id, err)
+			}
+			return
+		}
+		t := cur.Terminals[id]
+		t.FirmwareVersion = d.Terminal.Firmware.Version
+		cur.Terminals[id] = t
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// Run polls every Interval until ctx is done, reporting the events to
+// OnEvent and the failed polls to OnError. It returns the error of ctx.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Poll(ctx); err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the watcher in the background and returns its events. The
+// channel is closed once ctx is done. OnEvent is called as well, if set.
+func (w *Watcher) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	onEvent := w.OnEvent
+	ww := &Watcher{
+		Client:   w.Client,
+		Store:    w.Store,
+		Interval: w.Interval,
+		Opt:      w.Opt,
+		Details:  w.Details,
+		Workers:  w.Workers,
+		OnError:  w.OnError,
+		OnEvent: func(ev Event) {
+			if onEvent != nil {
+				onEvent(ev)
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+			}
+		},
+	}
+	go func() {
+		defer close(ch)
+		ww.Run(ctx)
+	}()
+	return ch
+}
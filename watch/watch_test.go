@@ -0,0 +1,96 @@
+package watch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/amp360/amp360test"
+)
+
+func newServer(t *testing.T) *amp360test.Server {
+	t.Helper()
+	srv := amp360test.NewServer()
+	t.Cleanup(srv.Close)
+	srv.Seed(amp360test.Data{
+		Firmware: []amp360test.Firmware{
+			{ID: "f1", Version: "1.0.0"},
+			{ID: "f2", Version: "1.1.0"},
+		},
+		Terminals: []amp360test.Terminal{
+			{ID: 25, SerialNumber: "8000000002", Status: "Active", TemplateID: 814, FirmwareID: "f1"},
+			{ID: 26, SerialNumber: "8000000003", Status: "Active", TemplateID: 814, FirmwareID: "f1"},
+		},
+	})
+	return srv
+}
+
+func TestPoll(t *testing.T) {
+	srv := newServer(t)
+	w := &Watcher{Client: srv.Client(), Details: true}
+	ctx := context.Background()
+
+	events, err := w.Poll(ctx)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("baseline events got %v, error %v", events, err)
+	}
+
+	term, _ := srv.Terminal(25)
+	term.Status, term.FirmwareID, term.TemplateID, term.ClientID = "Inactive", "f2", 902, "c2"
+	term.UpdatedAt = term.UpdatedAt.Add(time.Minute)
+	srv.Seed(amp360test.Data{Terminals: []amp360test.Terminal{term, {ID: 27, SerialNumber: "8000000004"}}})
+	if err := srv.Client().TerminalsService.Delete(ctx, 26); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []Event
+	w.OnEvent = func(ev Event) { seen = append(seen, ev) }
+	events, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Error occured = %v", err)
+	}
+	want := []Event{
+		{Type: StatusChanged, From: "Active", To: "Inactive"},
+		{Type: FirmwareChanged, From: "1.0.0", To: "1.1.0"},
+		{Type: TemplateChanged, From: "814", To: "902"},
+		{Type: ClientChanged, From: "", To: "c2"},
+		{Type: TerminalDeleted},
+		{Type: TerminalCreated},
+	}
+	if len(events) != len(want) || len(seen) != len(want) {
+		t.Fatalf("events got %v, want %d", events, len(want))
+	}
+	for i, ev := range events {
+		if ev.Type != want[i].Type || ev.From != want[i].From || ev.To != want[i].To {
+			t.Errorf("event %d got %v, want %v", i, ev, want[i])
+		}
+	}
+	if events[4].Terminal.ID != 26 || events[5].Terminal.SerialNumber != "8000000004" {
+		t.Errorf("create/delete events got %v, %v", events[4], events[5])
+	}
+
+	if events, err := w.Poll(ctx); err != nil || len(events) != 0 {
+		t.Errorf("events without changes got %v, error %v", events, err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	srv := newServer(t)
+	store := &MemoryStore{}
+	w := &Watcher{Client: srv.Client(), Store: store, Interval: 10 * time.Millisecond}
+	if _, err := w.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv.Seed(amp360test.Data{Terminals: []amp360test.Terminal{{ID: 30, SerialNumber: "8000000009"}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch := w.Events(ctx)
+	ev := <-ch
+	if ev.Type != TerminalCreated || ev.Terminal.ID != 30 {
+		t.Errorf("event got %v", ev)
+	}
+	cancel()
+	for range ch {
+	}
+}